@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	binanceadapter "cryptobot/internal/adapters/exchange/binance"
 	bitgetadapter "cryptobot/internal/adapters/exchange/bitget"
@@ -12,17 +19,47 @@ import (
 	kucoinadapter "cryptobot/internal/adapters/exchange/kucoin"
 	okxadapter "cryptobot/internal/adapters/exchange/okx"
 
+	"cryptobot/internal/config"
 	"cryptobot/internal/domain"
+	"cryptobot/internal/infra/exchangebooks"
+	"cryptobot/internal/transport/cli"
 	"cryptobot/internal/usecase"
+	"cryptobot/internal/usecase/export"
+	"cryptobot/internal/usecase/planner"
 )
 
 func main() {
-	cfg := domain.Config{
-		Limit:   100,
-		DelayMS: 100,
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "JSON-конфиг (переменные окружения важнее файла)")
+	exportPath := flag.String("export", "", "сохранить сравнение сценариев в файл (.csv, .xlsx, .pdf)")
+	watch := flag.Bool("watch", false, "полноэкранный режим наблюдения с обновлением")
+	interval := flag.Duration("interval", 5*time.Second, "период обновления в режиме --watch")
+	flag.Parse()
+
+	appCfg, err := config.Load(*configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Ошибка конфигурации: %v\n", err)
+		os.Exit(cli.ExitUsage)
+	}
+	cli.Coins = appCfg.Coins
+	cli.DefaultTimeout = time.Duration(appCfg.Timeouts.CLI)
+	svc := planner.New(exchangebooks.NewHTTPRepoWith(appCfg.Repo())).WithPolicy(appCfg.Policy())
+
+	// неинтерактивный режим: app [--config file] plan|compare|book|symbols [flags]
+	if args := flag.Args(); len(args) > 0 && cli.IsCommand(args[0]) {
+		os.Exit(cli.RunCommand(svc, args, os.Stdout, os.Stderr))
+	}
+
+	if *exportPath != "" {
+		if err := runExport(svc, *exportPath); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Ошибка выполнения: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	exchanges := []domain.Exchange{
+	cfg := appCfg.Domain()
+
+	all := []domain.Exchange{
 		binanceadapter.New(cfg),
 		bybitadapter.New(cfg),
 		okxadapter.New(cfg),
@@ -31,9 +68,51 @@ func main() {
 		htxadapter.New(cfg),
 		gateadapter.New(cfg),
 	}
+	enabled := map[string]bool{}
+	for _, name := range appCfg.EnabledExchanges() {
+		enabled[name] = true
+	}
+	var exchanges []domain.Exchange
+	for _, ex := range all {
+		if enabled[strings.ToLower(ex.Name())] {
+			exchanges = append(exchanges, ex)
+		}
+	}
 
-	if err := usecase.Run(cfg, exchanges); err != nil {
+	if *watch {
+		params := cli.GetInteractiveParams()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := usecase.Watch(ctx, cfg, exchanges, usecase.WatchOptions{Params: params, Interval: *interval, Route: svc}); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Ошибка выполнения: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := usecase.Run(cfg, exchanges, svc); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Ошибка выполнения: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// runExport — опрос как в интерактивном режиме, расчёт всех сценариев через planner и выгрузка в файл.
+func runExport(svc *planner.Service, path string) error {
+	if _, err := export.ParseFormat(filepath.Ext(path)); err != nil {
+		return err
+	}
+	params := cli.GetInteractiveParams()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cli.DefaultTimeout)
+	defer cancel()
+
+	res, err := svc.Compare(ctx, params.PlanRequest())
+	if err != nil {
+		return err
+	}
+	if err := cli.WriteCompareFile(path, res); err != nil {
+		return err
+	}
+	fmt.Printf("\nСравнение сценариев сохранено: %s\n", path)
+	return nil
+}
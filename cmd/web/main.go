@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -9,15 +11,48 @@ import (
 	"time"
 
 	"cryptobot/internal/app/webserver"
+	"cryptobot/internal/config"
 )
 
 func main() {
-	addr := os.Getenv("HTTP_ADDR")
-	if addr == "" {
-		addr = ":8080"
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "JSON-конфиг (переменные окружения важнее файла)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("init: %v", err)
+	}
+
+	opts := webserver.Options{
+		Addr:              cfg.Web.Addr,
+		PlansPath:         cfg.Web.PlansFile,
+		Repo:              cfg.Repo(),
+		Policy:            cfg.Policy(),
+		Coins:             cfg.Coins,
+		RequestTimeout:    time.Duration(cfg.Timeouts.Request),
+		BookCacheTTL:      time.Duration(cfg.Web.BookCacheTTL),
+		Prefetch:          cfg.Prefetch(),
+		DriftThresholdPct: cfg.Web.DriftThresholdPct, // > 0 включает фоновую проверку дрейфа
+		DriftWindow:       time.Duration(cfg.Web.DriftWindow),
+		DriftInterval:     time.Duration(cfg.Web.DriftInterval),
+		QuoteValidity:     time.Duration(cfg.Web.QuoteValidity),
+		QuoteTiers:        cfg.Web.QuoteTiers,
 	}
 
-	srv := webserver.New(addr)
+	// Котировки подписываются QUOTE_SECRET; без него ключ случайный и живёт до рестарта
+	opts.QuoteSecret = []byte(cfg.Web.QuoteSecret)
+	if len(opts.QuoteSecret) == 0 {
+		opts.QuoteSecret = make([]byte, 32)
+		if _, err := rand.Read(opts.QuoteSecret); err != nil {
+			log.Fatalf("init: quote secret: %v", err)
+		}
+		log.Println("QUOTE_SECRET is not set: quotes will not verify after restart")
+	}
+
+	srv, err := webserver.New(opts)
+	if err != nil {
+		log.Fatalf("init: %v", err)
+	}
 
 	go func() {
 		if err := srv.Start(); err != nil {
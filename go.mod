@@ -2,13 +2,15 @@ module cryptobot
 
 go 1.24.5
 
-require github.com/adshao/go-binance/v2 v2.8.5
+require (
+	github.com/adshao/go-binance/v2 v2.8.5
+	github.com/shopspring/decimal v1.4.0
+)
 
 require (
 	github.com/bitly/go-simplejson v0.5.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/jpillora/backoff v1.0.0 // indirect
-	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/stretchr/testify v1.9.0 // indirect
 )
@@ -2,14 +2,18 @@ package binanceadapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 
 	"cryptobot/internal/domain"
 	"cryptobot/internal/shared/retry"
 
 	gbinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
 )
 
 type BinanceExchange struct {
@@ -20,7 +24,8 @@ type BinanceExchange struct {
 func New(config domain.Config) *BinanceExchange {
 	client := gbinance.NewClient("", "")
 	// Чуть мягче таймаут: не висим долго, но и не рвём слишком быстро
-	client.HTTPClient = &http.Client{Timeout: 7 * time.Second}
+	client.HTTPClient = &http.Client{Timeout: config.HTTPTimeout(7 * time.Second)}
+	client.BaseURL = config.BaseURL("binance", client.BaseURL)
 	return &BinanceExchange{client: client, config: config}
 }
 
@@ -55,12 +60,13 @@ func (b *BinanceExchange) GetOrderBook(symbol string, limit int) (*domain.OrderB
 
 	var depth *gbinance.DepthResponse
 	// 2 попытки по 5s — компромисс между скоростью и стабильностью
-	err := retry.WithRetry(2, 500*time.Millisecond, func() error {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	attempts, backoff := b.config.Retry(2, 500*time.Millisecond)
+	err := retry.Do(context.Background(), retry.Policy{Attempts: attempts, Backoff: backoff}, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 		var err error
 		depth, err = b.client.NewDepthService().Symbol(symbol).Limit(chosen).Do(ctx)
-		return err
+		return classify(err)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("binance: стакан %s (limit=%d): %w", symbol, chosen, err)
@@ -71,11 +77,21 @@ func (b *BinanceExchange) GetOrderBook(symbol string, limit int) (*domain.OrderB
 		Exchange:  b.Name(),
 		Timestamp: time.Now().UnixMilli(),
 	}
-	for _, a := range depth.Asks {
-		ob.Asks = append(ob.Asks, domain.Order{Price: a.Price, Quantity: a.Quantity})
+	ob.Asks = make([]domain.Order, 0, len(depth.Asks))
+	for i, a := range depth.Asks {
+		o, err := domain.ParseOrder(a.Price, a.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("binance: стакан %s: asks: уровень %d: %w", symbol, i+1, err)
+		}
+		ob.Asks = append(ob.Asks, o)
 	}
-	for _, d := range depth.Bids {
-		ob.Bids = append(ob.Bids, domain.Order{Price: d.Price, Quantity: d.Quantity})
+	ob.Bids = make([]domain.Order, 0, len(depth.Bids))
+	for i, d := range depth.Bids {
+		o, err := domain.ParseOrder(d.Price, d.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("binance: стакан %s: bids: уровень %d: %w", symbol, i+1, err)
+		}
+		ob.Bids = append(ob.Bids, o)
 	}
 	return ob, nil
 }
@@ -102,3 +118,29 @@ func (b *BinanceExchange) GetMultipleOrderBooks(symbols []string, limit int, del
 	}
 	return res, nil
 }
+
+// codeTooManyRequests — Binance: превышен вес запросов, при повторных нарушениях IP банится.
+const codeTooManyRequests = -1003
+
+var bannedUntilRe = regexp.MustCompile(`banned until (\d+)`)
+
+// classify раскладывает ошибку go-binance для retry.Do. Клиент не отдаёт HTTP-статус
+// и заголовки, поэтому ориентируемся на код ошибки API: -1003 — лимит (с временем бана
+// из текста, если он есть), прочие коды API — постоянные ошибки, остальное — сетевые/5xx.
+func classify(err error) error {
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsValid() {
+		return err
+	}
+	if apiErr.Code != codeTooManyRequests {
+		return retry.Permanent(err)
+	}
+	he := &retry.HTTPError{Status: http.StatusTooManyRequests, Text: "429 " + apiErr.Message}
+	if m := bannedUntilRe.FindStringSubmatch(apiErr.Message); m != nil {
+		if ms, perr := strconv.ParseInt(m[1], 10, 64); perr == nil {
+			he.Status, he.Text = http.StatusTeapot, "418 "+apiErr.Message
+			he.RetryAfter = time.Until(time.UnixMilli(ms))
+		}
+	}
+	return he
+}
@@ -1,6 +1,7 @@
 package bitgetadapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,25 +19,33 @@ import (
 type httpClient struct {
 	baseURL string
 	client  *http.Client
+	retry   retry.Policy
 }
 
-func newHTTPClient() *httpClient {
+func newHTTPClient(cfg domain.Config) *httpClient {
+	attempts, backoff := cfg.Retry(2, 400*time.Millisecond)
 	return &httpClient{
-		baseURL: "https://api.bitget.com",
-		client:  &http.Client{Timeout: 8 * time.Second},
+		baseURL: cfg.BaseURL("bitget", "https://api.bitget.com"),
+		client:  &http.Client{Timeout: cfg.HTTPTimeout(8 * time.Second)},
+		retry:   retry.Policy{Attempts: attempts, Backoff: backoff},
 	}
 }
 
 func (c *httpClient) get(url string) ([]byte, error) {
 	var body []byte
-	err := retry.WithRetry(2, 400*time.Millisecond, func() error {
-		resp, err := c.client.Get(url)
+	// domain.Exchange не принимает ctx — запрос ограничен таймаутом клиента
+	err := retry.Do(context.Background(), c.retry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP %s", resp.Status)
+		if err := retry.CheckResponse(resp); err != nil {
+			return err
 		}
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -55,7 +64,7 @@ type bitgetExchange struct {
 
 func New(cfg domain.Config) domain.Exchange {
 	return &bitgetExchange{
-		http: newHTTPClient(),
+		http: newHTTPClient(cfg),
 	}
 }
 
@@ -126,15 +135,11 @@ func (b *bitgetExchange) GetOrderBook(symbol string, limit int) (*domain.OrderBo
 		Exchange:  b.Name(),
 		Timestamp: time.Now().UnixMilli(),
 	}
-	for _, a := range resp.Data.Asks {
-		if len(a) >= 2 {
-			ob.Asks = append(ob.Asks, domain.Order{Price: a[0], Quantity: a[1]})
-		}
+	if ob.Asks, err = domain.ParseOrders(resp.Data.Asks); err != nil {
+		return nil, fmt.Errorf("bitget: bad depth level: %s: %w", "asks", err)
 	}
-	for _, d := range resp.Data.Bids {
-		if len(d) >= 2 {
-			ob.Bids = append(ob.Bids, domain.Order{Price: d[0], Quantity: d[1]})
-		}
+	if ob.Bids, err = domain.ParseOrders(resp.Data.Bids); err != nil {
+		return nil, fmt.Errorf("bitget: bad depth level: %s: %w", "bids", err)
 	}
 	return ob, nil
 }
@@ -1,6 +1,7 @@
 package bybitadapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,25 +15,33 @@ import (
 type httpClient struct {
 	baseURL string
 	client  *http.Client
+	retry   retry.Policy
 }
 
-func newHTTPClient() *httpClient {
+func newHTTPClient(cfg domain.Config) *httpClient {
+	attempts, backoff := cfg.Retry(2, 400*time.Millisecond)
 	return &httpClient{
-		baseURL: "https://api.bybit.com",
-		client:  &http.Client{Timeout: 7 * time.Second}, // мягкий таймаут
+		baseURL: cfg.BaseURL("bybit", "https://api.bybit.com"),
+		client:  &http.Client{Timeout: cfg.HTTPTimeout(7 * time.Second)}, // мягкий таймаут
+		retry:   retry.Policy{Attempts: attempts, Backoff: backoff},
 	}
 }
 
 func (c *httpClient) get(url string) ([]byte, error) {
 	var body []byte
-	err := retry.WithRetry(2, 400*time.Millisecond, func() error {
-		resp, err := c.client.Get(url)
+	// domain.Exchange не принимает ctx — запрос ограничен таймаутом клиента
+	err := retry.Do(context.Background(), c.retry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP %s", resp.Status)
+		if err := retry.CheckResponse(resp); err != nil {
+			return err
 		}
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -51,7 +60,7 @@ type bybitExchange struct {
 
 func New(config domain.Config) domain.Exchange {
 	return &bybitExchange{
-		http:   newHTTPClient(),
+		http:   newHTTPClient(config),
 		config: config,
 	}
 }
@@ -134,15 +143,11 @@ func (b *bybitExchange) GetOrderBook(symbol string, limit int) (*domain.OrderBoo
 		Exchange:  b.Name(),
 		Timestamp: resp.Result.Ts,
 	}
-	for _, a := range resp.Result.Asks {
-		if len(a) >= 2 {
-			ob.Asks = append(ob.Asks, domain.Order{Price: a[0], Quantity: a[1]})
-		}
+	if ob.Asks, err = domain.ParseOrders(resp.Result.Asks); err != nil {
+		return nil, fmt.Errorf("bybit: битый уровень стакана: %s: %w", "asks", err)
 	}
-	for _, d := range resp.Result.Bids {
-		if len(d) >= 2 {
-			ob.Bids = append(ob.Bids, domain.Order{Price: d[0], Quantity: d[1]})
-		}
+	if ob.Bids, err = domain.ParseOrders(resp.Result.Bids); err != nil {
+		return nil, fmt.Errorf("bybit: битый уровень стакана: %s: %w", "bids", err)
 	}
 	return ob, nil
 }
@@ -1,6 +1,7 @@
 package htxadapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -21,25 +22,33 @@ func toHTXSymbol(unified string) string {
 type httpClient struct {
 	baseURL string
 	client  *http.Client
+	retry   retry.Policy
 }
 
-func newHTTPClient() *httpClient {
+func newHTTPClient(cfg domain.Config) *httpClient {
+	attempts, backoff := cfg.Retry(2, 400*time.Millisecond)
 	return &httpClient{
-		baseURL: "https://api.huobi.pro",
-		client:  &http.Client{Timeout: 8 * time.Second},
+		baseURL: cfg.BaseURL("htx", "https://api.huobi.pro"),
+		client:  &http.Client{Timeout: cfg.HTTPTimeout(8 * time.Second)},
+		retry:   retry.Policy{Attempts: attempts, Backoff: backoff},
 	}
 }
 
 func (c *httpClient) get(url string) ([]byte, error) {
 	var body []byte
-	err := retry.WithRetry(2, 400*time.Millisecond, func() error {
-		resp, err := c.client.Get(url)
+	// domain.Exchange не принимает ctx — запрос ограничен таймаутом клиента
+	err := retry.Do(context.Background(), c.retry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP %s", resp.Status)
+		if err := retry.CheckResponse(resp); err != nil {
+			return err
 		}
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -58,7 +67,7 @@ type htxExchange struct {
 
 func New(cfg domain.Config) domain.Exchange {
 	return &htxExchange{
-		http:   newHTTPClient(),
+		http:   newHTTPClient(cfg),
 		config: cfg,
 	}
 }
@@ -127,23 +136,19 @@ func (h *htxExchange) GetOrderBook(symbol string, limit int) (*domain.OrderBook,
 		Exchange:  h.Name(),
 		Timestamp: resp.Ts,
 	}
-	appendLevels := func(dst *[]domain.Order, src [][]float64) {
-		n := limit
-		if n <= 0 || n > len(src) {
-			n = len(src)
-		}
-		for i := 0; i < n; i++ {
-			r := src[i]
-			if len(r) >= 2 {
-				*dst = append(*dst, domain.Order{
-					Price:    fmt.Sprintf("%.8f", r[0]),
-					Quantity: fmt.Sprintf("%.8f", r[1]),
-				})
-			}
+	// HTX отдаёт уровни числами — разбирать строки не нужно
+	levels := func(src [][]float64) ([]domain.Order, error) {
+		if limit > 0 && limit < len(src) {
+			src = src[:limit]
 		}
+		return domain.OrdersFromFloats(src)
+	}
+	if ob.Asks, err = levels(resp.Tick.Asks); err != nil {
+		return nil, fmt.Errorf("htx: bad depth level: asks: %w", err)
+	}
+	if ob.Bids, err = levels(resp.Tick.Bids); err != nil {
+		return nil, fmt.Errorf("htx: bad depth level: bids: %w", err)
 	}
-	appendLevels(&ob.Asks, resp.Tick.Asks)
-	appendLevels(&ob.Bids, resp.Tick.Bids)
 	return ob, nil
 }
 
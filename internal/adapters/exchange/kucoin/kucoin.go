@@ -1,6 +1,7 @@
 package kucoinadapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,25 +28,33 @@ func toKuCoinSymbol(unified string) string {
 type httpClient struct {
 	baseURL string
 	client  *http.Client
+	retry   retry.Policy
 }
 
-func newHTTPClient() *httpClient {
+func newHTTPClient(cfg domain.Config) *httpClient {
+	attempts, backoff := cfg.Retry(2, 400*time.Millisecond)
 	return &httpClient{
-		baseURL: "https://api.kucoin.com",
-		client:  &http.Client{Timeout: 8 * time.Second},
+		baseURL: cfg.BaseURL("kucoin", "https://api.kucoin.com"),
+		client:  &http.Client{Timeout: cfg.HTTPTimeout(8 * time.Second)},
+		retry:   retry.Policy{Attempts: attempts, Backoff: backoff},
 	}
 }
 
 func (c *httpClient) get(url string) ([]byte, error) {
 	var body []byte
-	err := retry.WithRetry(2, 400*time.Millisecond, func() error {
-		resp, err := c.client.Get(url)
+	// domain.Exchange не принимает ctx — запрос ограничен таймаутом клиента
+	err := retry.Do(context.Background(), c.retry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP %s", resp.Status)
+		if err := retry.CheckResponse(resp); err != nil {
+			return err
 		}
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -64,7 +73,7 @@ type kucoinExchange struct {
 
 func New(cfg domain.Config) domain.Exchange {
 	return &kucoinExchange{
-		http:   newHTTPClient(),
+		http:   newHTTPClient(cfg),
 		config: cfg,
 	}
 }
@@ -134,20 +143,18 @@ func (k *kucoinExchange) GetOrderBook(symbol string, limit int) (*domain.OrderBo
 		Timestamp: time.Now().UnixMilli(),
 	}
 	// Ограничим до limit вручную
-	appendLevels := func(dst *[]domain.Order, src [][]string) {
-		n := limit
-		if n <= 0 || n > len(src) {
-			n = len(src)
-		}
-		for i := 0; i < n; i++ {
-			r := src[i]
-			if len(r) >= 2 {
-				*dst = append(*dst, domain.Order{Price: r[0], Quantity: r[1]})
-			}
+	levels := func(src [][]string) ([]domain.Order, error) {
+		if limit > 0 && limit < len(src) {
+			src = src[:limit]
 		}
+		return domain.ParseOrders(src)
+	}
+	if ob.Asks, err = levels(resp.Data.Asks); err != nil {
+		return nil, fmt.Errorf("kucoin: bad orderbook level: asks: %w", err)
+	}
+	if ob.Bids, err = levels(resp.Data.Bids); err != nil {
+		return nil, fmt.Errorf("kucoin: bad orderbook level: bids: %w", err)
 	}
-	appendLevels(&ob.Asks, resp.Data.Asks)
-	appendLevels(&ob.Bids, resp.Data.Bids)
 	return ob, nil
 }
 
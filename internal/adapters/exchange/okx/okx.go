@@ -1,6 +1,7 @@
 package okxadapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,25 +32,33 @@ func toOKXSymbol(unified string) string {
 type httpClient struct {
 	baseURL string
 	client  *http.Client
+	retry   retry.Policy
 }
 
-func newHTTPClient() *httpClient {
+func newHTTPClient(cfg domain.Config) *httpClient {
+	attempts, backoff := cfg.Retry(2, 400*time.Millisecond)
 	return &httpClient{
-		baseURL: "https://www.okx.com",
-		client:  &http.Client{Timeout: 7 * time.Second},
+		baseURL: cfg.BaseURL("okx", "https://www.okx.com"),
+		client:  &http.Client{Timeout: cfg.HTTPTimeout(7 * time.Second)},
+		retry:   retry.Policy{Attempts: attempts, Backoff: backoff},
 	}
 }
 
 func (c *httpClient) get(url string) ([]byte, error) {
 	var body []byte
-	err := retry.WithRetry(2, 400*time.Millisecond, func() error {
-		resp, err := c.client.Get(url)
+	// domain.Exchange не принимает ctx — запрос ограничен таймаутом клиента
+	err := retry.Do(context.Background(), c.retry, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
 			return err
 		}
 		defer func() { _ = resp.Body.Close() }()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP %s", resp.Status)
+		if err := retry.CheckResponse(resp); err != nil {
+			return err
 		}
 		b, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -68,7 +77,7 @@ type okxExchange struct {
 
 func New(config domain.Config) domain.Exchange {
 	return &okxExchange{
-		http:   newHTTPClient(),
+		http:   newHTTPClient(config),
 		config: config,
 	}
 }
@@ -148,15 +157,11 @@ func (o *okxExchange) GetOrderBook(symbol string, limit int) (*domain.OrderBook,
 		Exchange:  o.Name(),
 		Timestamp: ts,
 	}
-	for _, a := range resp.Data[0].Asks {
-		if len(a) >= 2 {
-			ob.Asks = append(ob.Asks, domain.Order{Price: a[0], Quantity: a[1]})
-		}
+	if ob.Asks, err = domain.ParseOrders(resp.Data[0].Asks); err != nil {
+		return nil, fmt.Errorf("okx: битый уровень стакана: %s: %w", "asks", err)
 	}
-	for _, b := range resp.Data[0].Bids {
-		if len(b) >= 2 {
-			ob.Bids = append(ob.Bids, domain.Order{Price: b[0], Quantity: b[1]})
-		}
+	if ob.Bids, err = domain.ParseOrders(resp.Data[0].Bids); err != nil {
+		return nil, fmt.Errorf("okx: битый уровень стакана: %s: %w", "bids", err)
 	}
 	return ob, nil
 }
@@ -1,16 +1,100 @@
 package webserver
 
 import (
+	"context"
+	"time"
+
+	"cryptobot/internal/infra/bookcache"
 	"cryptobot/internal/infra/exchangebooks"
+	"cryptobot/internal/infra/planstore"
 	"cryptobot/internal/transport/httpapi"
 	"cryptobot/internal/usecase/planner"
+	"cryptobot/internal/usecase/quoting"
 )
 
-func New(addr string) *httpapi.Server {
+// Options — параметры сборки веб-сервера.
+type Options struct {
+	Addr string
+	// Файл истории планов (пусто — история отключена). Файл только растёт:
+	// каждый план и каждый сценарий /api/compare — отдельная запись.
+	PlansPath string
+
+	Repo           exchangebooks.Options     // биржи, URL, таймауты и повторы
+	Policy         planner.Policy            // сценарий по умолчанию, комиссии, лимиты
+	Coins          []string                  // монеты для /api/symbols
+	RequestTimeout time.Duration             // таймаут запросов API, которые ходят на биржи
+	BookCacheTTL   time.Duration             // сколько живут стаканы в кэше (0 — только склейка запросов)
+	Prefetch       bookcache.PrefetchOptions // фоновый прогрев (без монет и TopN — выключен)
+
+	// Фоновая проверка дрейфа котировок (нужна история; порог <= 0 — выключена)
+	DriftThresholdPct float64
+	DriftWindow       time.Duration
+	DriftInterval     time.Duration
+
+	// Клиентские котировки (/api/quotes): пустой секрет — котирование выключено
+	QuoteSecret   []byte
+	QuoteValidity time.Duration
+	QuoteTiers    map[string]quoting.Markup
+}
+
+func New(opts Options) (*httpapi.Server, error) {
 	// Инфраструктура: тянем стаканы <COIN>/USDT по HTTP с бирж
-	repo := exchangebooks.NewHTTPRepo()
+	repo := exchangebooks.NewHTTPRepoWith(opts.Repo)
+	// Одновременные запросы одной монеты делят один поход на биржи
+	books := bookcache.New(repo, opts.BookCacheTTL)
 	// Чистый use-case планировщика
-	svc := planner.New(repo)
+	svc := planner.New(books).WithPolicy(opts.Policy)
+	var store *planstore.FileStore
+	if opts.PlansPath != "" {
+		// История планов: каждый расчёт получает ID и ссылку /api/plans/{id}
+		var err error
+		if store, err = planstore.Open(opts.PlansPath); err != nil {
+			return nil, err
+		}
+		svc.WithStore(store)
+	}
 	// Адаптер между httpapi и planner.Service
-	return httpapi.New(addr, &httpapi.PlannerAdapter{Svc: svc})
+	adapter := &httpapi.PlannerAdapter{Svc: svc, Venues: repo, Cache: books}
+	srv := httpapi.New(opts.Addr, adapter).
+		WithCoins(opts.Coins).
+		WithRequestTimeout(opts.RequestTimeout)
+	if store != nil {
+		srv.OnClose(store.Close)
+	}
+
+	if len(opts.Prefetch.Coins) > 0 || opts.Prefetch.TopN > 0 {
+		// Горячие монеты обновляются заранее; при упоре в лимиты бирж — реже
+		pf := bookcache.NewPrefetcher(books, opts.Prefetch).WithLimits(repo)
+		adapter.Prefetch = pf
+		ctx, cancel := context.WithCancel(context.Background())
+		go pf.Run(ctx)
+		srv.OnShutdown(cancel)
+	}
+
+	if len(opts.QuoteSecret) > 0 {
+		qs, err := quoting.New(svc, quoting.Config{
+			Secret:   opts.QuoteSecret,
+			Validity: opts.QuoteValidity,
+			Tiers:    opts.QuoteTiers,
+		})
+		if err != nil {
+			return nil, err
+		}
+		srv.WithQuotes(&httpapi.QuotingAdapter{Svc: qs})
+	}
+
+	if opts.PlansPath != "" && opts.DriftThresholdPct > 0 {
+		window, interval := opts.DriftWindow, opts.DriftInterval
+		if window <= 0 {
+			window = 15 * time.Minute
+		}
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		adapter.Monitor = planner.NewDriftMonitor(svc, opts.DriftThresholdPct, window, interval)
+		ctx, cancel := context.WithCancel(context.Background())
+		go adapter.Monitor.Run(ctx)
+		srv.OnShutdown(cancel)
+	}
+	return srv, nil
 }
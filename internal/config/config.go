@@ -0,0 +1,457 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/infra/bookcache"
+	"cryptobot/internal/infra/exchangebooks"
+	"cryptobot/internal/usecase/planner"
+	"cryptobot/internal/usecase/quoting"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// Config — настройки обоих бинарников (cmd/app и cmd/web).
+// Источники по приоритету: переменные окружения → JSON-файл → Default().
+type Config struct {
+	Exchanges       map[string]Exchange `json:"exchanges"` // ключ — имя биржи в нижнем регистре
+	Timeouts        Timeouts            `json:"timeouts"`
+	Depth           int                 `json:"depth"`    // глубина стакана (0 — максимум биржи)
+	DelayMS         int                 `json:"delay_ms"` // пауза между запросами символов в CLI
+	Retry           Retry               `json:"retry"`
+	Breaker         Breaker             `json:"breaker"`
+	Coins           []string            `json:"coins"` // монеты к USDT; сам USDT добавляется автоматически
+	DefaultScenario string              `json:"default_scenario"`
+	FeesBps         map[string]float64  `json:"fees_bps"`   // тейкер-комиссия по биржам, б.п.
+	RiskBps         map[string]float64  `json:"risk_bps"`   // штраф за риск биржи в optimal, б.п.
+	MaxAmount       map[string]float64  `json:"max_amount"` // лимит суммы заявки по валюте оплаты
+	Sanity          Sanity              `json:"sanity"`
+	MaxStale        Duration            `json:"max_stale"` // стаканы старше не идут в расчёт; "0s" — без проверки
+	Scenarios       Scenarios           `json:"scenarios"`
+	Web             Web                 `json:"web"`
+}
+
+// Exchange — настройки одной биржи.
+type Exchange struct {
+	Enabled *bool  `json:"enabled,omitempty"` // nil — включена
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+type Timeouts struct {
+	HTTP    Duration `json:"http"`    // один HTTP-запрос к бирже
+	Request Duration `json:"request"` // запрос к API веб-сервера, который ходит на биржи
+	CLI     Duration `json:"cli"`     // команда CLI целиком
+}
+
+type Retry struct {
+	Attempts int      `json:"attempts"`
+	Backoff  Duration `json:"backoff"` // пауза перед второй попыткой, дальше удваивается
+}
+
+// Breaker — автомат на биржу: после Failures ошибок подряд биржа пропускается на Cooldown.
+type Breaker struct {
+	Failures int      `json:"failures"`
+	Cooldown Duration `json:"cooldown"`
+}
+
+// Sanity — проверка стаканов перед расчётом плана.
+type Sanity struct {
+	OutlierPct  float64 `json:"outlier_pct"`   // биржа с mid дальше этого % от медианы исключается
+	MinSideUSDT float64 `json:"min_side_usdt"` // более тонкая сторона стакана помечается
+}
+
+// Scenarios — настройки отдельных сценариев.
+type Scenarios struct {
+	LiquidityBandPct float64 `json:"liquidity_band_pct"` // liquidity_split: глубина считается в этой полосе от лучшей цены, %
+	SlippageBps      float64 `json:"slippage_bps"`       // min_venues: допуск к VWAP optimal, б.п. (запрос может задать свой)
+}
+
+// Web — настройки cmd/web.
+type Web struct {
+	Addr              string                    `json:"addr"`
+	PlansFile         string                    `json:"plans_file"` // пусто (по умолчанию) — история отключена; файл только растёт
+	DriftThresholdPct float64                   `json:"drift_threshold_pct"`
+	DriftWindow       Duration                  `json:"drift_window"`
+	DriftInterval     Duration                  `json:"drift_interval"`
+	QuoteSecret       string                    `json:"quote_secret"`
+	QuoteValidity     Duration                  `json:"quote_validity"`
+	QuoteTiers        map[string]quoting.Markup `json:"quote_tiers"`
+	BookCacheTTL      Duration                  `json:"book_cache_ttl"` // 0 — только склейка одновременных запросов
+	Prefetch          Prefetch                  `json:"prefetch"`
+}
+
+// Prefetch — фоновый прогрев кэша стаканов; без монет и top_n выключен.
+type Prefetch struct {
+	Coins       []string `json:"coins"`        // прогреваются всегда
+	TopN        int      `json:"top_n"`        // плюс N самых запрашиваемых
+	Interval    Duration `json:"interval"`     // не больше book_cache_ttl, иначе кэш остывает
+	MaxInterval Duration `json:"max_interval"` // потолок при упоре в лимиты бирж
+}
+
+// Duration — time.Duration в JSON строкой ("5s", "1m30s").
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(time.Duration(d).String()) }
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default — значения, с которыми бинарники работали до появления конфига.
+func Default() Config {
+	cfg := Config{
+		Exchanges: map[string]Exchange{},
+		Timeouts: Timeouts{
+			HTTP:    Duration(8 * time.Second),
+			Request: Duration(10 * time.Second),
+			CLI:     Duration(15 * time.Second),
+		},
+		DelayMS:         100,
+		Retry:           Retry{Attempts: 2, Backoff: Duration(400 * time.Millisecond)},
+		Breaker:         Breaker{Failures: 3, Cooldown: Duration(30 * time.Second)},
+		Coins:           []string{"BTC", "ETH", "BNB", "SOL", "XRP", "ADA", "DOGE", "TON", "TRX", "DOT"},
+		DefaultScenario: "optimal",
+		Sanity:          Sanity{OutlierPct: 2, MinSideUSDT: 1000},
+		MaxStale:        Duration(10 * time.Second),
+		Scenarios:       Scenarios{LiquidityBandPct: 1, SlippageBps: 10},
+		Web: Web{
+			Addr:          ":8080",
+			DriftWindow:   Duration(15 * time.Minute),
+			DriftInterval: Duration(time.Minute),
+			BookCacheTTL:  Duration(time.Second),
+			Prefetch:      Prefetch{Interval: Duration(5 * time.Second), MaxInterval: Duration(time.Minute)},
+		},
+	}
+	for _, name := range exchangebooks.Exchanges() {
+		cfg.Exchanges[name] = Exchange{}
+	}
+	return cfg
+}
+
+// Load читает JSON-файл (пустой путь — только умолчания), применяет переменные окружения
+// и проверяет результат. Неизвестные поля в файле — ошибка.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("config: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return Config{}, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, fmt.Errorf("config: %w", err)
+	}
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) normalize() {
+	exs := make(map[string]Exchange, len(c.Exchanges))
+	for name, ex := range c.Exchanges {
+		exs[strings.ToLower(strings.TrimSpace(name))] = ex
+	}
+	c.Exchanges = exs
+
+	for i, coin := range c.Coins {
+		c.Coins[i] = strings.ToUpper(strings.TrimSpace(coin))
+	}
+	for i, coin := range c.Web.Prefetch.Coins {
+		c.Web.Prefetch.Coins[i] = strings.ToUpper(strings.TrimSpace(coin))
+	}
+	c.DefaultScenario = strings.ToLower(strings.TrimSpace(c.DefaultScenario))
+
+	fees := make(map[string]float64, len(c.FeesBps))
+	for name, v := range c.FeesBps {
+		fees[strings.ToLower(strings.TrimSpace(name))] = v
+	}
+	c.FeesBps = fees
+
+	risk := make(map[string]float64, len(c.RiskBps))
+	for name, v := range c.RiskBps {
+		risk[strings.ToLower(strings.TrimSpace(name))] = v
+	}
+	c.RiskBps = risk
+
+	caps := make(map[string]float64, len(c.MaxAmount))
+	for asset, v := range c.MaxAmount {
+		caps[strings.ToUpper(strings.TrimSpace(asset))] = v
+	}
+	c.MaxAmount = caps
+}
+
+var tickerRe = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// Validate проверяет конфиг и возвращает все найденные ошибки сразу.
+func (c Config) Validate() error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	known := map[string]bool{}
+	for _, name := range exchangebooks.Exchanges() {
+		known[name] = true
+	}
+	for _, name := range sortedKeys(c.Exchanges) {
+		ex := c.Exchanges[name]
+		if !known[name] {
+			bad("exchanges.%s: unknown exchange (supported: %s)", name, strings.Join(exchangebooks.Exchanges(), ", "))
+			continue
+		}
+		if ex.BaseURL != "" {
+			u, err := url.Parse(ex.BaseURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				bad("exchanges.%s.base_url: %q is not an http(s) URL", name, ex.BaseURL)
+			}
+		}
+	}
+	if len(c.EnabledExchanges()) == 0 {
+		bad("exchanges: at least one exchange must be enabled")
+	}
+
+	if c.Timeouts.HTTP <= 0 {
+		bad("timeouts.http must be > 0")
+	}
+	if c.Timeouts.Request <= 0 {
+		bad("timeouts.request must be > 0")
+	}
+	if c.Timeouts.CLI <= 0 {
+		bad("timeouts.cli must be > 0")
+	}
+	if c.Depth < 0 {
+		bad("depth must be >= 0")
+	}
+	if c.DelayMS < 0 {
+		bad("delay_ms must be >= 0")
+	}
+	if c.Retry.Attempts < 1 {
+		bad("retry.attempts must be >= 1")
+	}
+	if c.Retry.Backoff < 0 {
+		bad("retry.backoff must be >= 0")
+	}
+	if c.Breaker.Failures < 1 {
+		bad("breaker.failures must be >= 1")
+	}
+	if c.Breaker.Cooldown <= 0 {
+		bad("breaker.cooldown must be > 0")
+	}
+
+	if len(c.Coins) == 0 {
+		bad("coins: list is empty")
+	}
+	seen := map[string]bool{}
+	for _, coin := range c.Coins {
+		switch {
+		case coin == "USDT":
+			bad("coins: USDT is the quote currency and is added automatically")
+		case !tickerRe.MatchString(coin):
+			bad("coins: invalid ticker %q", coin)
+		case seen[coin]:
+			bad("coins: duplicate %s", coin)
+		}
+		seen[coin] = true
+	}
+
+	validScenario := false
+	for _, id := range planner.Scenarios() {
+		validScenario = validScenario || id == c.DefaultScenario
+	}
+	if !validScenario {
+		bad("default_scenario: unknown %q (supported: %s)", c.DefaultScenario, strings.Join(planner.Scenarios(), ", "))
+	}
+
+	for _, name := range sortedKeys(c.FeesBps) {
+		v := c.FeesBps[name]
+		if !known[name] {
+			bad("fees_bps.%s: unknown exchange", name)
+		}
+		if v < 0 || v > 1000 {
+			bad("fees_bps.%s: %g is out of range 0..1000", name, v)
+		}
+	}
+	for _, name := range sortedKeys(c.RiskBps) {
+		v := c.RiskBps[name]
+		if !known[name] {
+			bad("risk_bps.%s: unknown exchange", name)
+		}
+		if v < 0 || v > 1000 {
+			bad("risk_bps.%s: %g is out of range 0..1000", name, v)
+		}
+	}
+	for _, asset := range sortedKeys(c.MaxAmount) {
+		v := c.MaxAmount[asset]
+		if asset != "USDT" && !seen[asset] {
+			bad("max_amount.%s: not in coins", asset)
+		}
+		if v <= 0 {
+			bad("max_amount.%s must be > 0", asset)
+		}
+	}
+
+	if strings.TrimSpace(c.Web.Addr) == "" {
+		bad("web.addr is empty")
+	}
+	if c.MaxStale < 0 {
+		bad("max_stale must be >= 0")
+	}
+	if c.Sanity.OutlierPct < 0 || c.Sanity.MinSideUSDT < 0 {
+		bad("sanity: thresholds must be >= 0")
+	}
+	if c.Scenarios.LiquidityBandPct < 0 || c.Scenarios.LiquidityBandPct >= 100 {
+		bad("scenarios.liquidity_band_pct must be in [0, 100)")
+	}
+	if c.Scenarios.SlippageBps < 0 {
+		bad("scenarios.slippage_bps must be >= 0")
+	}
+	if c.Web.DriftThresholdPct < 0 {
+		bad("web.drift_threshold_pct must be >= 0")
+	}
+	if c.Web.DriftWindow < 0 || c.Web.DriftInterval < 0 || c.Web.QuoteValidity < 0 || c.Web.BookCacheTTL < 0 {
+		bad("web: durations must be >= 0")
+	}
+	if pf := c.Web.Prefetch; len(pf.Coins) > 0 || pf.TopN > 0 {
+		for _, coin := range pf.Coins {
+			if !tickerRe.MatchString(coin) || coin == "USDT" {
+				bad("web.prefetch.coins: invalid ticker %q", coin)
+			}
+		}
+		if pf.Interval <= 0 {
+			bad("web.prefetch.interval must be > 0")
+		} else if pf.Interval > c.Web.BookCacheTTL {
+			bad("web.prefetch.interval (%s) must be <= web.book_cache_ttl (%s), otherwise prefetched books expire before use",
+				time.Duration(pf.Interval), time.Duration(c.Web.BookCacheTTL))
+		}
+		if pf.MaxInterval < pf.Interval {
+			bad("web.prefetch.max_interval must be >= web.prefetch.interval")
+		}
+	}
+	if c.Web.Prefetch.TopN < 0 {
+		bad("web.prefetch.top_n must be >= 0")
+	}
+	for _, tier := range sortedKeys(c.Web.QuoteTiers) {
+		if m := c.Web.QuoteTiers[tier]; m.Bps < 0 || m.Fixed < 0 {
+			bad("web.quote_tiers.%s: markup must be >= 0", tier)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnabledExchanges — включённые биржи в порядке опроса.
+func (c Config) EnabledExchanges() []string {
+	var out []string
+	for _, name := range exchangebooks.Exchanges() {
+		ex, ok := c.Exchanges[name]
+		if ok && (ex.Enabled == nil || *ex.Enabled) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (c Config) baseURLs() map[string]string {
+	out := map[string]string{}
+	for name, ex := range c.Exchanges {
+		if ex.BaseURL != "" {
+			out[name] = ex.BaseURL
+		}
+	}
+	return out
+}
+
+// Domain — настройки адаптеров бирж для интерактивного CLI.
+func (c Config) Domain() domain.Config {
+	return domain.Config{
+		Limit:         100,
+		DelayMS:       c.DelayMS,
+		BaseURLs:      c.baseURLs(),
+		Timeout:       time.Duration(c.Timeouts.HTTP),
+		RetryAttempts: c.Retry.Attempts,
+		RetryBackoff:  time.Duration(c.Retry.Backoff),
+	}
+}
+
+// Repo — настройки HTTPRepo для planner.
+func (c Config) Repo() exchangebooks.Options {
+	return exchangebooks.Options{
+		Exchanges:     c.EnabledExchanges(),
+		BaseURLs:      c.baseURLs(),
+		Timeout:       time.Duration(c.Timeouts.HTTP),
+		Depth:         c.Depth,
+		RetryAttempts: c.Retry.Attempts,
+		RetryBackoff:  time.Duration(c.Retry.Backoff),
+
+		BreakerFailures: c.Breaker.Failures,
+		BreakerCooldown: time.Duration(c.Breaker.Cooldown),
+	}
+}
+
+// Prefetch — фоновый прогрев кэша стаканов для cmd/web.
+func (c Config) Prefetch() bookcache.PrefetchOptions {
+	return bookcache.PrefetchOptions{
+		Coins:       c.Web.Prefetch.Coins,
+		TopN:        c.Web.Prefetch.TopN,
+		Interval:    time.Duration(c.Web.Prefetch.Interval),
+		MaxInterval: time.Duration(c.Web.Prefetch.MaxInterval),
+	}
+}
+
+// Policy — сценарий по умолчанию, комиссии и лимиты для planner.
+func (c Config) Policy() planner.Policy {
+	maxStale := time.Duration(c.MaxStale)
+	if maxStale == 0 {
+		maxStale = -1 // в конфиге 0 выключает проверку, в planner 0 — умолчание
+	}
+	return planner.Policy{
+		DefaultScenario: c.DefaultScenario,
+		FeesBps:         c.FeesBps,
+		MaxAmount:       c.MaxAmount,
+		Sanity: planner.Sanity{
+			OutlierPct:  c.Sanity.OutlierPct,
+			MinSideUSDT: c.Sanity.MinSideUSDT,
+		},
+		MaxStale: maxStale,
+		Scenario: scenario.Params{
+			LiquidityBandPct: c.Scenarios.LiquidityBandPct,
+			SlippageBps:      c.Scenarios.SlippageBps,
+			RiskBps:          c.RiskBps,
+		},
+	}
+}
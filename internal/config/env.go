@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv накладывает переменные окружения поверх файла:
+//
+//	EXCHANGES=binance,okx       — включённые биржи (остальные выключаются)
+//	<EXCHANGE>_BASE_URL=...     — например OKX_BASE_URL
+//	HTTP_TIMEOUT, REQUEST_TIMEOUT, CLI_TIMEOUT, BOOK_DEPTH, RETRY_ATTEMPTS, RETRY_BACKOFF,
+//	BREAKER_FAILURES, BREAKER_COOLDOWN
+//	COINS=BTC,ETH  DEFAULT_SCENARIO=optimal
+//	FEES_BPS='{"binance":10}'  RISK_BPS='{"htx":15}'  MAX_AMOUNT='{"USDT":5000000}'
+//	SANITY_OUTLIER_PCT, SANITY_MIN_SIDE_USDT, MAX_STALE, LIQUIDITY_BAND_PCT, SLIPPAGE_BPS
+//	HTTP_ADDR, PLANS_FILE, DRIFT_THRESHOLD_PCT, DRIFT_WINDOW, DRIFT_INTERVAL,
+//	QUOTE_SECRET, QUOTE_VALIDITY, QUOTE_TIERS='{"default":{"bps":30}}', BOOK_CACHE_TTL,
+//	PREFETCH_COINS=BTC,ETH  PREFETCH_TOP_N, PREFETCH_INTERVAL, PREFETCH_MAX_INTERVAL
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	get := func(key string) (string, bool) {
+		v, ok := lookup(key)
+		v = strings.TrimSpace(v)
+		return v, ok && v != ""
+	}
+	dur := func(key string, dst *Duration) error {
+		if v, ok := get(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = Duration(d)
+		}
+		return nil
+	}
+	integer := func(key string, dst *int) error {
+		if v, ok := get(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = n
+		}
+		return nil
+	}
+	number := func(key string, dst *float64) error {
+		if v, ok := get(key); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			*dst = f
+		}
+		return nil
+	}
+	jsonVar := func(key string, dst any) error {
+		if v, ok := get(key); ok {
+			if err := json.Unmarshal([]byte(v), dst); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		return nil
+	}
+
+	if v, ok := get("EXCHANGES"); ok {
+		on := map[string]bool{}
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				on[name] = true
+				if _, ok := c.Exchanges[name]; !ok {
+					c.Exchanges[name] = Exchange{} // неизвестное имя поймает Validate
+				}
+			}
+		}
+		for name, ex := range c.Exchanges {
+			enabled := on[name]
+			ex.Enabled = &enabled
+			c.Exchanges[name] = ex
+		}
+	}
+	for name, ex := range c.Exchanges {
+		if v, ok := get(strings.ToUpper(name) + "_BASE_URL"); ok {
+			ex.BaseURL = v
+			c.Exchanges[name] = ex
+		}
+	}
+
+	steps := []error{
+		dur("HTTP_TIMEOUT", &c.Timeouts.HTTP),
+		dur("REQUEST_TIMEOUT", &c.Timeouts.Request),
+		dur("CLI_TIMEOUT", &c.Timeouts.CLI),
+		integer("BOOK_DEPTH", &c.Depth),
+		integer("RETRY_ATTEMPTS", &c.Retry.Attempts),
+		dur("RETRY_BACKOFF", &c.Retry.Backoff),
+		integer("BREAKER_FAILURES", &c.Breaker.Failures),
+		dur("BREAKER_COOLDOWN", &c.Breaker.Cooldown),
+		jsonVar("FEES_BPS", &c.FeesBps),
+		jsonVar("RISK_BPS", &c.RiskBps),
+		jsonVar("MAX_AMOUNT", &c.MaxAmount),
+		number("SANITY_OUTLIER_PCT", &c.Sanity.OutlierPct),
+		number("SANITY_MIN_SIDE_USDT", &c.Sanity.MinSideUSDT),
+		dur("MAX_STALE", &c.MaxStale),
+		number("LIQUIDITY_BAND_PCT", &c.Scenarios.LiquidityBandPct),
+		number("SLIPPAGE_BPS", &c.Scenarios.SlippageBps),
+		dur("DRIFT_WINDOW", &c.Web.DriftWindow),
+		dur("DRIFT_INTERVAL", &c.Web.DriftInterval),
+		dur("QUOTE_VALIDITY", &c.Web.QuoteValidity),
+		jsonVar("QUOTE_TIERS", &c.Web.QuoteTiers),
+		dur("BOOK_CACHE_TTL", &c.Web.BookCacheTTL),
+		integer("PREFETCH_TOP_N", &c.Web.Prefetch.TopN),
+		dur("PREFETCH_INTERVAL", &c.Web.Prefetch.Interval),
+		dur("PREFETCH_MAX_INTERVAL", &c.Web.Prefetch.MaxInterval),
+	}
+	for _, err := range steps {
+		if err != nil {
+			return err
+		}
+	}
+
+	if v, ok := get("COINS"); ok {
+		c.Coins = nil
+		for _, coin := range strings.Split(v, ",") {
+			if coin = strings.TrimSpace(coin); coin != "" {
+				c.Coins = append(c.Coins, coin)
+			}
+		}
+	}
+	if v, ok := get("PREFETCH_COINS"); ok {
+		c.Web.Prefetch.Coins = nil
+		for _, coin := range strings.Split(v, ",") {
+			if coin = strings.TrimSpace(coin); coin != "" {
+				c.Web.Prefetch.Coins = append(c.Web.Prefetch.Coins, coin)
+			}
+		}
+	}
+	if v, ok := get("DEFAULT_SCENARIO"); ok {
+		c.DefaultScenario = v
+	}
+	if v, ok := get("HTTP_ADDR"); ok {
+		c.Web.Addr = v
+	}
+	if v, ok := get("PLANS_FILE"); ok {
+		c.Web.PlansFile = v
+	}
+	if v, ok := get("DRIFT_THRESHOLD_PCT"); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("DRIFT_THRESHOLD_PCT: %w", err)
+		}
+		c.Web.DriftThresholdPct = f
+	}
+	if v, ok := get("QUOTE_SECRET"); ok {
+		c.Web.QuoteSecret = v
+	}
+	return nil
+}
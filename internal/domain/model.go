@@ -1,25 +1,76 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Базовые доменные сущности
 
+// Order — уровень стакана. Строки биржи разбираются в числа один раз, на входе
+// (см. ParseOrders); дальше весь расчёт работает с числами.
 type Order struct {
-	Price    string
-	Quantity string
+	Price float64 // цена
+	Qty   float64 // количество
 }
 
 type OrderBook struct {
 	Symbol    string
 	Exchange  string
-	Timestamp int64
+	Timestamp int64 // время стакана по бирже: мс (или с у части адаптеров)
 	Asks      []Order
 	Bids      []Order
 }
 
+// Time — Timestamp как time.Time; нулевое время, если биржа его не сообщила.
+func (ob *OrderBook) Time() time.Time {
+	switch {
+	case ob.Timestamp <= 0:
+		return time.Time{}
+	case ob.Timestamp > 1e12:
+		return time.UnixMilli(ob.Timestamp)
+	default:
+		return time.Unix(ob.Timestamp, 0)
+	}
+}
+
 type Config struct {
 	DelayMS int `json:"delay_ms"`
 	Limit   int `json:"limit"`
+
+	// Переопределения для адаптеров; нулевые значения — умолчания адаптера
+	BaseURLs      map[string]string `json:"base_urls,omitempty"` // ключ — имя биржи в нижнем регистре
+	Timeout       time.Duration     `json:"-"`                   // HTTP-таймаут запроса к бирже
+	RetryAttempts int               `json:"-"`
+	RetryBackoff  time.Duration     `json:"-"`
+}
+
+// BaseURL — базовый URL биржи из конфига или def.
+func (c Config) BaseURL(exchange, def string) string {
+	if u := c.BaseURLs[strings.ToLower(exchange)]; u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return def
+}
+
+// HTTPTimeout — таймаут из конфига или def.
+func (c Config) HTTPTimeout(def time.Duration) time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return def
+}
+
+// Retry — число попыток и начальная пауза из конфига или умолчания адаптера.
+func (c Config) Retry(defAttempts int, defBackoff time.Duration) (int, time.Duration) {
+	attempts, backoff := defAttempts, defBackoff
+	if c.RetryAttempts > 0 {
+		attempts = c.RetryAttempts
+	}
+	if c.RetryBackoff > 0 {
+		backoff = c.RetryBackoff
+	}
+	return attempts, backoff
 }
 
 type Exchange interface {
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseOrder разбирает уровень стакана из строк биржи. Нечисловые и бесконечные
+// значения, цена <= 0 и отрицательный объём — ошибка: битый уровень не должен
+// молча выпадать из расчёта.
+func ParseOrder(price, qty string) (Order, error) {
+	p, err := parseNumber(price)
+	if err != nil {
+		return Order{}, fmt.Errorf("цена %q: %w", price, err)
+	}
+	q, err := parseNumber(qty)
+	if err != nil {
+		return Order{}, fmt.Errorf("объём %q: %w", qty, err)
+	}
+	return NewOrder(p, q)
+}
+
+// NewOrder — уровень из чисел (у бирж, которые отдают стакан числами JSON),
+// с теми же проверками, что у ParseOrder.
+func NewOrder(price, qty float64) (Order, error) {
+	switch {
+	case math.IsNaN(price) || math.IsInf(price, 0):
+		return Order{}, fmt.Errorf("цена %v: не конечное число", price)
+	case math.IsNaN(qty) || math.IsInf(qty, 0):
+		return Order{}, fmt.Errorf("объём %v: не конечное число", qty)
+	case price <= 0:
+		return Order{}, fmt.Errorf("цена %v: должна быть больше нуля", price)
+	case qty < 0:
+		return Order{}, fmt.Errorf("объём %v: отрицательный", qty)
+	}
+	return Order{Price: price, Qty: qty}, nil
+}
+
+// ParseOrders разбирает сторону стакана вида [[price, qty, ...], ...].
+// Ошибка указывает номер первого битого уровня (с единицы).
+func ParseOrders(rows [][]string) ([]Order, error) {
+	out := make([]Order, 0, len(rows))
+	for i, r := range rows {
+		if len(r) < 2 {
+			return nil, fmt.Errorf("уровень %d: ожидалось [цена, объём], получено %d полей", i+1, len(r))
+		}
+		o, err := ParseOrder(r[0], r[1])
+		if err != nil {
+			return nil, fmt.Errorf("уровень %d: %w", i+1, err)
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// OrdersFromFloats — то же, что ParseOrders, для стакана вида [[price, qty], ...] числами.
+func OrdersFromFloats(rows [][]float64) ([]Order, error) {
+	out := make([]Order, 0, len(rows))
+	for i, r := range rows {
+		if len(r) < 2 {
+			return nil, fmt.Errorf("уровень %d: ожидалось [цена, объём], получено %d полей", i+1, len(r))
+		}
+		o, err := NewOrder(r[0], r[1])
+		if err != nil {
+			return nil, fmt.Errorf("уровень %d: %w", i+1, err)
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, errors.New("не число")
+	}
+	return v, nil
+}
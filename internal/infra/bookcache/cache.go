@@ -0,0 +1,181 @@
+package bookcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+// Repo — декоратор planner.Repo: одинаковые одновременные запросы (монета, глубина)
+// склеиваются в один поход на биржи, а результат живёт ttl.
+// Стаканы отдаются всем вызывающим общими — planner их не меняет.
+type Repo struct {
+	next planner.Repo
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[key]*entry
+	usage   map[string]int64 // запросы по монетам с прошлого TakeUsage
+
+	hits, shared, misses, prefetches atomic.Int64
+}
+
+// Stats — счётчики кэша с запуска.
+type Stats struct {
+	Hits       int64 // отдано из кэша
+	Shared     int64 // присоединились к уже идущей загрузке
+	Misses     int64 // пошли на биржи сами
+	Prefetches int64 // фоновые обновления (в долю попаданий не входят)
+	HitRatio   float64
+}
+
+type key struct {
+	coin  string
+	depth int
+}
+
+type entry struct {
+	done chan struct{} // закрывается, когда загрузка завершена
+
+	books     []planner.Book
+	diags     []string
+	err       error
+	fetchedAt time.Time
+}
+
+// New оборачивает next; ttl <= 0 — только склейка одновременных запросов, без кэша.
+func New(next planner.Repo, ttl time.Duration) *Repo {
+	return &Repo{next: next, ttl: ttl, now: time.Now, entries: map[key]*entry{}, usage: map[string]int64{}}
+}
+
+func (r *Repo) FetchAllBooks(ctx context.Context, coin string, depth int) ([]planner.Book, []string, error) {
+	k := key{coin: coin, depth: depth}
+
+	r.mu.Lock()
+	r.usage[coin]++
+	e, ok := r.entries[k]
+	leader := !ok || (e.loaded() && !r.fresh(e))
+	switch {
+	case leader:
+		r.misses.Add(1)
+		e = r.start(ctx, k)
+	case e.loaded():
+		r.hits.Add(1)
+	default:
+		r.shared.Add(1)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	diags := append([]string(nil), e.diags...)
+	if !leader {
+		age := r.now().Sub(e.fetchedAt)
+		for _, b := range e.books {
+			diags = append(diags, fmt.Sprintf("%s:cache-age=%dms", b.Exchange, age.Milliseconds()))
+		}
+	}
+	return append([]planner.Book(nil), e.books...), diags, nil
+}
+
+// Refresh — фоновое обновление: загружает стаканы заново, даже если в кэше они ещё
+// свежие, и кладёт их в кэш. Пока идёт загрузка, запросы получают прежние стаканы;
+// если загрузка уже идёт по запросу пользователя, Refresh просто дожидается её.
+func (r *Repo) Refresh(ctx context.Context, coin string, depth int) error {
+	k := key{coin: coin, depth: depth}
+	r.mu.Lock()
+	e, ok := r.entries[k]
+	switch {
+	case ok && !e.loaded():
+	case ok && r.fresh(e):
+		r.prefetches.Add(1)
+		e = &entry{done: make(chan struct{})}
+		go r.load(context.WithoutCancel(ctx), k, e) // подменит запись, когда загрузится
+	default:
+		r.prefetches.Add(1)
+		e = r.start(ctx, k)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TakeUsage — сколько раз спрашивали каждую монету с прошлого вызова; счётчики обнуляются.
+func (r *Repo) TakeUsage() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.usage
+	r.usage = map[string]int64{}
+	return out
+}
+
+func (r *Repo) Stats() Stats {
+	st := Stats{
+		Hits:       r.hits.Load(),
+		Shared:     r.shared.Load(),
+		Misses:     r.misses.Load(),
+		Prefetches: r.prefetches.Load(),
+	}
+	if total := st.Hits + st.Shared + st.Misses; total > 0 {
+		st.HitRatio = float64(st.Hits+st.Shared) / float64(total)
+	}
+	return st
+}
+
+// start заводит запись и запускает загрузку; вызывается под r.mu.
+func (r *Repo) start(ctx context.Context, k key) *entry {
+	e := &entry{done: make(chan struct{})}
+	r.entries[k] = e
+	// загрузка не зависит от отмены ctx первого вызывающего: её ждут и другие
+	go r.load(context.WithoutCancel(ctx), k, e)
+	return e
+}
+
+func (r *Repo) load(ctx context.Context, k key, e *entry) {
+	books, diags, err := r.next.FetchAllBooks(ctx, k.coin, k.depth)
+
+	r.mu.Lock()
+	e.books, e.diags, e.err, e.fetchedAt = books, diags, err, r.now()
+	cur := r.entries[k]
+	switch {
+	case err != nil || r.ttl <= 0:
+		// ошибки не кэшируем; без ttl запись нужна только на время загрузки
+		if cur == e {
+			delete(r.entries, k)
+		}
+	case cur != e && (cur == nil || cur.loaded()):
+		r.entries[k] = e // фоновое обновление подменяет прежние стаканы
+	}
+	r.mu.Unlock()
+	close(e.done)
+}
+
+// fresh — запись загружена и ещё не старше ttl; вызывается под r.mu.
+func (r *Repo) fresh(e *entry) bool {
+	return e.loaded() && r.now().Sub(e.fetchedAt) < r.ttl
+}
+
+func (e *entry) loaded() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
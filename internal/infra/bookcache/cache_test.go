@@ -0,0 +1,232 @@
+package bookcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+// fakeRepo считает походы на биржи; пока gate не закрыт, загрузка висит.
+// Стаканы помечены номером загрузки: "a#1", "b#1", потом "a#2"...
+type fakeRepo struct {
+	calls atomic.Int32
+	gate  chan struct{} // nil — отвечает сразу
+	err   error
+}
+
+func (f *fakeRepo) FetchAllBooks(_ context.Context, _ string, _ int) ([]planner.Book, []string, error) {
+	n := f.calls.Add(1)
+	if f.gate != nil {
+		<-f.gate
+	}
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return []planner.Book{{Exchange: fmt.Sprintf("a#%d", n)}, {Exchange: fmt.Sprintf("b#%d", n)}}, []string{"c:err"}, nil
+}
+
+// clock — подменяемое время кэша.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func newRepo(next planner.Repo, ttl time.Duration) (*Repo, *clock) {
+	c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := New(next, ttl)
+	r.now = c.now
+	return r, c
+}
+
+// waitFor ждёт, пока cond станет истинным (горутины дошли до нужной точки).
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func cacheAges(diags []string) []string {
+	var out []string
+	for _, d := range diags {
+		if strings.Contains(d, ":cache-age=") {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func TestFetchSequential(t *testing.T) {
+	errDown := errors.New("exchange down")
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		err        error
+		advance    time.Duration // между первым и вторым запросом
+		wantCalls  int32
+		wantHits   int64
+		wantMisses int64
+		wantAges   []string // cache-age второго запроса
+	}{
+		{name: "hit within ttl", ttl: 2 * time.Second, advance: 1500 * time.Millisecond, wantCalls: 1, wantHits: 1, wantMisses: 1,
+			wantAges: []string{"a#1:cache-age=1500ms", "b#1:cache-age=1500ms"}},
+		{name: "expired refetches", ttl: 2 * time.Second, advance: 2 * time.Second, wantCalls: 2, wantMisses: 2},
+		{name: "ttl <= 0 does not cache", ttl: 0, wantCalls: 2, wantMisses: 2},
+		{name: "errors are not cached", ttl: time.Minute, err: errDown, wantCalls: 2, wantMisses: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeRepo{err: tt.err}
+			r, c := newRepo(next, tt.ttl)
+			ctx := context.Background()
+
+			if _, _, err := r.FetchAllBooks(ctx, "BTC", 0); !errors.Is(err, tt.err) {
+				t.Fatalf("first err = %v", err)
+			}
+			c.advance(tt.advance)
+			books, diags, err := r.FetchAllBooks(ctx, "BTC", 0)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("second err = %v", err)
+			}
+			if got := next.calls.Load(); got != tt.wantCalls {
+				t.Errorf("exchange calls = %d, want %d", got, tt.wantCalls)
+			}
+			st := r.Stats()
+			if st.Hits != tt.wantHits || st.Misses != tt.wantMisses || st.Shared != 0 {
+				t.Errorf("stats = %+v", st)
+			}
+			if got := cacheAges(diags); fmt.Sprint(got) != fmt.Sprint(tt.wantAges) {
+				t.Errorf("cache-age diags = %v, want %v", got, tt.wantAges)
+			}
+			if tt.err == nil && len(books) != 2 {
+				t.Errorf("books = %+v", books)
+			}
+		})
+	}
+}
+
+func TestFetchCoalesces(t *testing.T) {
+	for _, ttl := range []time.Duration{0, time.Minute} {
+		t.Run(fmt.Sprintf("ttl=%s", ttl), func(t *testing.T) {
+			next := &fakeRepo{gate: make(chan struct{})}
+			r, _ := newRepo(next, ttl)
+
+			const callers = 5
+			type out struct {
+				books []planner.Book
+				ages  int
+				err   error
+			}
+			results := make(chan out, callers)
+			fetch := func() {
+				books, diags, err := r.FetchAllBooks(context.Background(), "ETH", 0)
+				results <- out{books, len(cacheAges(diags)), err}
+			}
+			go fetch()
+			waitFor(t, func() bool { return next.calls.Load() == 1 })
+			for i := 1; i < callers; i++ {
+				go fetch()
+			}
+			waitFor(t, func() bool { return r.Stats().Shared == callers-1 })
+			close(next.gate)
+
+			followers := 0
+			for i := 0; i < callers; i++ {
+				o := <-results
+				if o.err != nil || len(o.books) != 2 || o.books[0].Exchange != "a#1" {
+					t.Fatalf("result = %+v", o)
+				}
+				if o.ages > 0 {
+					followers++ // лидер стаканы загрузил сам — cache-age только у присоединившихся
+				}
+			}
+			if followers != callers-1 {
+				t.Errorf("callers with cache-age = %d, want %d", followers, callers-1)
+			}
+			if got := next.calls.Load(); got != 1 {
+				t.Errorf("exchange calls = %d, want 1", got)
+			}
+			if st := r.Stats(); st.Misses != 1 || st.Shared != callers-1 || st.HitRatio != 0.8 {
+				t.Errorf("stats = %+v", st)
+			}
+		})
+	}
+}
+
+// Лидер отменил запрос, пока остальные ждут: загрузка продолжается и достаётся им.
+func TestFetchLeaderCancels(t *testing.T) {
+	next := &fakeRepo{gate: make(chan struct{})}
+	r, _ := newRepo(next, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := r.FetchAllBooks(ctx, "SOL", 0)
+		leaderErr <- err
+	}()
+	waitFor(t, func() bool { return next.calls.Load() == 1 })
+
+	followerErr := make(chan error, 1)
+	go func() {
+		_, _, err := r.FetchAllBooks(context.Background(), "SOL", 0)
+		followerErr <- err
+	}()
+	waitFor(t, func() bool { return r.Stats().Shared == 1 })
+
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader err = %v", err)
+	}
+	close(next.gate)
+	if err := <-followerErr; err != nil {
+		t.Fatalf("follower err = %v", err)
+	}
+	// загрузка не оборвалась вместе с лидером и легла в кэш
+	if _, _, err := r.FetchAllBooks(context.Background(), "SOL", 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("exchange calls = %d, want 1", got)
+	}
+	if st := r.Stats(); st.Hits != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestTakeUsage(t *testing.T) {
+	r, _ := newRepo(&fakeRepo{}, time.Minute)
+	for _, coin := range []string{"BTC", "BTC", "ETH"} {
+		if _, _, err := r.FetchAllBooks(context.Background(), coin, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.TakeUsage(); got["BTC"] != 2 || got["ETH"] != 1 || len(got) != 2 {
+		t.Errorf("usage = %v", got)
+	}
+	if got := r.TakeUsage(); len(got) != 0 {
+		t.Errorf("usage after take = %v, want empty", got)
+	}
+}
@@ -0,0 +1,161 @@
+package bookcache
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PrefetchOptions — что и как часто прогревать; нулевые значения — умолчания.
+type PrefetchOptions struct {
+	Coins       []string      // прогреваются всегда
+	TopN        int           // плюс N самых запрашиваемых монет
+	Depth       int           // глубина, с которой их запрашивает planner (0 — максимум)
+	Interval    time.Duration // базовый интервал (по умолчанию 5s)
+	MaxInterval time.Duration // потолок при отступлении (по умолчанию 8×Interval)
+}
+
+// RateLimitSource — биржи, упёршиеся в лимит (exchangebooks.HTTPRepo).
+type RateLimitSource interface {
+	RateLimited() []string
+}
+
+// PrefetchStatus — снимок для /api/health.
+type PrefetchStatus struct {
+	Hot         []string
+	Interval    time.Duration // текущий, с учётом отступления
+	LastRun     time.Time
+	RateLimited []string
+}
+
+// usageDecay — на сколько за раунд забывается популярность монеты.
+const usageDecay = 0.5
+
+// Prefetcher по расписанию обновляет стаканы горячих монет в кэше, чтобы запросы
+// пользователей попадали в тёплый кэш. Если биржи упираются в лимит, интервал
+// удваивается до MaxInterval, а после спокойного раунда возвращается к базовому.
+type Prefetcher struct {
+	cache  *Repo
+	opts   PrefetchOptions
+	limits RateLimitSource
+
+	mu       sync.Mutex
+	scores   map[string]float64 // популярность монет с затуханием
+	status   PrefetchStatus
+	interval time.Duration
+}
+
+func NewPrefetcher(cache *Repo, opts PrefetchOptions) *Prefetcher {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = 8 * opts.Interval
+	}
+	return &Prefetcher{cache: cache, opts: opts, scores: map[string]float64{}, interval: opts.Interval}
+}
+
+// WithLimits подключает источник лимитов бирж для адаптивного интервала.
+func (p *Prefetcher) WithLimits(src RateLimitSource) *Prefetcher {
+	p.limits = src
+	return p
+}
+
+// Run крутится, пока не отменён ctx.
+func (p *Prefetcher) Run(ctx context.Context) {
+	for {
+		p.round(ctx)
+		p.mu.Lock()
+		wait := p.interval
+		p.mu.Unlock()
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (p *Prefetcher) round(ctx context.Context) {
+	hot := p.hotList()
+	var wg sync.WaitGroup
+	for _, coin := range hot {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.cache.Refresh(ctx, coin, p.opts.Depth); err != nil && ctx.Err() == nil {
+				log.Printf("prefetch %s: %v", coin, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var limited []string
+	if p.limits != nil {
+		limited = p.limits.RateLimited()
+	}
+	p.mu.Lock()
+	if len(limited) > 0 {
+		p.interval = min(p.interval*2, p.opts.MaxInterval)
+	} else {
+		p.interval = max(p.interval/2, p.opts.Interval)
+	}
+	p.status = PrefetchStatus{Hot: hot, Interval: p.interval, LastRun: time.Now(), RateLimited: limited}
+	p.mu.Unlock()
+}
+
+// hotList — настроенные монеты и TopN самых популярных из остальных.
+func (p *Prefetcher) hotList() []string {
+	usage := p.cache.TakeUsage()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for coin, s := range p.scores {
+		if s *= usageDecay; s < 0.01 {
+			delete(p.scores, coin)
+		} else {
+			p.scores[coin] = s
+		}
+	}
+	for coin, n := range usage {
+		p.scores[coin] += float64(n)
+	}
+
+	seen := map[string]bool{}
+	hot := make([]string, 0, len(p.opts.Coins)+p.opts.TopN)
+	for _, coin := range p.opts.Coins {
+		if !seen[coin] {
+			seen[coin] = true
+			hot = append(hot, coin)
+		}
+	}
+	var popular []string
+	for coin := range p.scores {
+		if !seen[coin] {
+			popular = append(popular, coin)
+		}
+	}
+	sort.Slice(popular, func(i, j int) bool {
+		if p.scores[popular[i]] != p.scores[popular[j]] {
+			return p.scores[popular[i]] > p.scores[popular[j]]
+		}
+		return popular[i] < popular[j]
+	})
+	if len(popular) > p.opts.TopN {
+		popular = popular[:p.opts.TopN]
+	}
+	return append(hot, popular...)
+}
+
+func (p *Prefetcher) Status() PrefetchStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.status
+	st.Interval = p.interval
+	return st
+}
@@ -0,0 +1,171 @@
+package bookcache
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Фоновое обновление свежей записи: пока загрузка идёт, запросы получают прежние
+// стаканы без ожидания, а по её окончании — новые.
+func TestRefreshSwapsInFreshEntry(t *testing.T) {
+	next := &fakeRepo{}
+	r, _ := newRepo(next, time.Minute)
+	ctx := context.Background()
+	if _, _, err := r.FetchAllBooks(ctx, "BTC", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	next.gate = make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- r.Refresh(ctx, "BTC", 0) }()
+	waitFor(t, func() bool { return next.calls.Load() == 2 })
+
+	books, _, err := r.FetchAllBooks(ctx, "BTC", 0)
+	if err != nil || books[0].Exchange != "a#1" {
+		t.Fatalf("during refresh: books = %+v, err = %v; want the previous books", books, err)
+	}
+	close(next.gate)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	books, _, err = r.FetchAllBooks(ctx, "BTC", 0)
+	if err != nil || books[0].Exchange != "a#2" {
+		t.Fatalf("after refresh: books = %+v, err = %v; want the refreshed books", books, err)
+	}
+	if st := r.Stats(); st.Prefetches != 1 || st.Misses != 1 || st.Hits != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name           string
+		warm           bool // в кэше уже есть запись
+		inFlight       bool // идёт загрузка по запросу пользователя
+		wantCalls      int32
+		wantPrefetches int64
+	}{
+		{name: "cold cache loads", wantCalls: 1, wantPrefetches: 1},
+		{name: "fresh entry reloads", warm: true, wantCalls: 2, wantPrefetches: 1},
+		{name: "joins user load", inFlight: true, wantCalls: 1, wantPrefetches: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeRepo{}
+			r, _ := newRepo(next, time.Minute)
+			ctx := context.Background()
+			if tt.warm {
+				_, _, _ = r.FetchAllBooks(ctx, "ETH", 0)
+			}
+			user := make(chan error, 1)
+			if tt.inFlight {
+				next.gate = make(chan struct{})
+				go func() { _, _, err := r.FetchAllBooks(ctx, "ETH", 0); user <- err }()
+				waitFor(t, func() bool { return next.calls.Load() == 1 })
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- r.Refresh(ctx, "ETH", 0) }()
+			if tt.inFlight {
+				time.Sleep(10 * time.Millisecond) // Refresh дошёл до ожидания загрузки
+				close(next.gate)
+				if err := <-user; err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+			if got := next.calls.Load(); got != tt.wantCalls {
+				t.Errorf("exchange calls = %d, want %d", got, tt.wantCalls)
+			}
+			if st := r.Stats(); st.Prefetches != tt.wantPrefetches {
+				t.Errorf("stats = %+v", st)
+			}
+		})
+	}
+}
+
+// Популярность монет затухает вдвое за раунд; в горячий список идут настроенные
+// монеты и TopN самых популярных из остальных.
+func TestHotList(t *testing.T) {
+	r, _ := newRepo(&fakeRepo{}, time.Minute)
+	p := NewPrefetcher(r, PrefetchOptions{Coins: []string{"BTC"}, TopN: 2})
+	ask := func(usage map[string]int) {
+		for coin, n := range usage {
+			for i := 0; i < n; i++ {
+				_, _, _ = r.FetchAllBooks(context.Background(), coin, 0)
+			}
+		}
+	}
+
+	rounds := []struct {
+		usage map[string]int
+		want  []string
+	}{
+		// равные очки — по имени
+		{usage: map[string]int{"BTC": 5, "XRP": 3, "ETH": 3, "SOL": 2}, want: []string{"BTC", "ETH", "XRP"}},
+		// ETH 1.5, XRP 1.5, SOL 1+3 = 4
+		{usage: map[string]int{"SOL": 3}, want: []string{"BTC", "SOL", "ETH"}},
+		// SOL 2, DOGE 1, ETH 0.75, XRP 0.75
+		{usage: map[string]int{"DOGE": 1}, want: []string{"BTC", "SOL", "DOGE"}},
+	}
+	for i, rd := range rounds {
+		ask(rd.usage)
+		if got := p.hotList(); !slices.Equal(got, rd.want) {
+			t.Errorf("round %d: hot = %v, want %v", i+1, got, rd.want)
+		}
+	}
+
+	// без запросов популярность забывается целиком, остаются настроенные монеты
+	for i := 0; i < 12; i++ {
+		p.hotList()
+	}
+	if got := p.hotList(); !slices.Equal(got, []string{"BTC"}) {
+		t.Errorf("hot after decay = %v", got)
+	}
+	if len(p.scores) != 0 {
+		t.Errorf("scores after decay = %v, want empty", p.scores)
+	}
+}
+
+// limitsFunc — источник лимитов из функции.
+type limitsFunc func() []string
+
+func (f limitsFunc) RateLimited() []string { return f() }
+
+func TestRoundAdaptiveInterval(t *testing.T) {
+	var limited []string
+	r, _ := newRepo(&fakeRepo{}, time.Minute)
+	p := NewPrefetcher(r, PrefetchOptions{Interval: time.Second, MaxInterval: 4 * time.Second}).
+		WithLimits(limitsFunc(func() []string { return limited }))
+
+	steps := []struct {
+		limited []string
+		want    time.Duration
+	}{
+		{limited: []string{"binance"}, want: 2 * time.Second},
+		{limited: []string{"binance"}, want: 4 * time.Second},
+		{limited: []string{"binance", "okx"}, want: 4 * time.Second}, // не выше MaxInterval
+		{want: 2 * time.Second},
+		{want: time.Second},
+		{want: time.Second}, // не ниже базового
+	}
+	for i, st := range steps {
+		limited = st.limited
+		p.round(context.Background())
+		got := p.Status()
+		if got.Interval != st.want || !slices.Equal(got.RateLimited, st.limited) || got.LastRun.IsZero() {
+			t.Errorf("step %d: status = %+v, want interval %s", i+1, got, st.want)
+		}
+	}
+}
+
+func TestNewPrefetcherDefaults(t *testing.T) {
+	p := NewPrefetcher(nil, PrefetchOptions{})
+	if p.opts.Interval != 5*time.Second || p.opts.MaxInterval != 40*time.Second || p.Status().Interval != 5*time.Second {
+		t.Errorf("opts = %+v, status = %+v", p.opts, p.Status())
+	}
+}
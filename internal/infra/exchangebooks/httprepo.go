@@ -4,59 +4,191 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/breaker"
+	"cryptobot/internal/shared/ratelimit"
+	"cryptobot/internal/shared/retry"
 	"cryptobot/internal/usecase/planner"
 )
 
+// exchanges — биржи, которые умеет опрашивать HTTPRepo, в порядке опроса.
+var exchanges = []struct {
+	name    string
+	baseURL string // по умолчанию
+	fetch   fetchFunc
+}{
+	{"binance", "https://api.binance.com", (*HTTPRepo).fetchBinance},
+	{"okx", "https://www.okx.com", (*HTTPRepo).fetchOKX},
+	{"bybit", "https://api.bybit.com", (*HTTPRepo).fetchBybit},
+	{"kucoin", "https://api.kucoin.com", (*HTTPRepo).fetchKucoin},
+	{"gate", "https://api.gateio.ws", (*HTTPRepo).fetchGate},
+	{"htx", "https://api.huobi.pro", (*HTTPRepo).fetchHTX},
+	{"bitget", "https://api.bitget.com", (*HTTPRepo).fetchBitget},
+}
+
+// Exchanges — имена поддерживаемых бирж.
+func Exchanges() []string {
+	out := make([]string, 0, len(exchanges))
+	for _, ex := range exchanges {
+		out = append(out, ex.name)
+	}
+	return out
+}
+
+// Options — настройки HTTPRepo; нулевые значения — умолчания.
+type Options struct {
+	Exchanges     []string          // включённые биржи (пусто — все)
+	BaseURLs      map[string]string // переопределения базовых URL по имени биржи
+	Timeout       time.Duration     // HTTP-таймаут одного запроса
+	Depth         int               // глубина стакана, если вызывающий передал 0
+	RetryAttempts int
+	RetryBackoff  time.Duration
+
+	// Автомат на биржу: после BreakerFailures ошибок подряд биржа пропускается BreakerCooldown
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
 // HTTPRepo реализует planner.Repo: тянет стаканы <COIN>/USDT с крупных бирж по HTTP.
 type HTTPRepo struct {
-	http *http.Client
+	http     *http.Client
+	fetchers []fetcher
+	depth    int
+	retry    retry.Policy
+}
+
+// fetchFunc тянет стакан <coin>/USDT с одной биржи через её fetcher (URL и лимитер).
+type fetchFunc func(r *HTTPRepo, ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string)
+
+type fetcher struct {
+	name    string
+	baseURL string
+	fetch   fetchFunc
+	breaker *breaker.Breaker
+	limit   venueLimit
+	bucket  *ratelimit.Bucket // общий для всех запросов к бирже
 }
 
 func NewHTTPRepo() *HTTPRepo {
-	return &HTTPRepo{http: &http.Client{Timeout: 8 * time.Second}}
+	return NewHTTPRepoWith(Options{})
+}
+
+func NewHTTPRepoWith(opts Options) *HTTPRepo {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 8 * time.Second
+	}
+	r := &HTTPRepo{
+		http:  &http.Client{Timeout: timeout},
+		depth: opts.Depth,
+		retry: retry.Policy{Attempts: opts.RetryAttempts, Backoff: opts.RetryBackoff},
+	}
+	failures, cooldown := opts.BreakerFailures, opts.BreakerCooldown
+	if failures <= 0 {
+		failures = 3
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+
+	enabled := map[string]bool{}
+	for _, ex := range opts.Exchanges {
+		enabled[strings.ToLower(strings.TrimSpace(ex))] = true
+	}
+	for _, ex := range exchanges {
+		if len(enabled) > 0 && !enabled[ex.name] {
+			continue
+		}
+		base := ex.baseURL
+		if u := opts.BaseURLs[ex.name]; u != "" {
+			base = strings.TrimRight(u, "/")
+		}
+		lim := limits[ex.name]
+		r.fetchers = append(r.fetchers, fetcher{
+			name:    ex.name,
+			baseURL: base,
+			fetch:   ex.fetch,
+			breaker: breaker.New(ex.name, failures, cooldown),
+			limit:   lim,
+			bucket:  ratelimit.NewBucket(lim.capacity, lim.window),
+		})
+	}
+	return r
 }
 
 // ====== Вспомогалки ======
 
-func (r *HTTPRepo) doGET(ctx context.Context, url string, target any) error {
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+// doGET — GET с повторами; каждая попытка сначала ждёт weight в бакете биржи.
+func (r *HTTPRepo) doGET(ctx context.Context, f *fetcher, weight float64, url string, target any) error {
+	return retry.Do(ctx, r.retry, func(ctx context.Context) error {
+		if err := f.bucket.Wait(ctx, weight); err != nil {
+			return err
+		}
+		return r.get(ctx, f, url, target)
+	})
+}
+
+func (r *HTTPRepo) get(ctx context.Context, f *fetcher, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return retry.Permanent(err)
+	}
 	req.Header.Set("User-Agent", "otccalc/httprepo")
 	res, err := r.http.Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
-	if res.StatusCode/100 != 2 {
-		return fmt.Errorf("http %d", res.StatusCode)
+	f.observe(res)
+	if err := retry.CheckResponse(res); err != nil {
+		return err
 	}
 	return json.NewDecoder(res.Body).Decode(target)
 }
 
-func sortAsks(xs []planner.Level) {
-	sort.Slice(xs, func(i, j int) bool { return xs[i].Price < xs[j].Price })
-}
-func sortBids(xs []planner.Level) {
-	sort.Slice(xs, func(i, j int) bool { return xs[i].Price > xs[j].Price })
+// parseSides разбирает обе стороны стакана; битый уровень — ошибка на весь стакан,
+// а не молча выпавший уровень.
+func parseSides(asks, bids [][]string) ([]planner.Level, []planner.Level, error) {
+	a, err := domain.ParseOrders(asks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("asks: %w", err)
+	}
+	b, err := domain.ParseOrders(bids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bids: %w", err)
+	}
+	return a, b, nil
 }
 
+// clampPositive отбрасывает пустые уровни (нулевой объём). Порядок уровней не
+// меняется: лестницу проверяет planner (Sanity), битая не должна тихо чиниться сортировкой.
 func clampPositive(xs []planner.Level) []planner.Level {
 	out := xs[:0]
 	for _, l := range xs {
-		if l.Price > 0 && l.Qty > 0 && isFinite(l.Price) && isFinite(l.Qty) {
+		if l.Qty > 0 {
 			out = append(out, l)
 		}
 	}
 	return out
 }
 
-func isFinite(x float64) bool { return !math.IsNaN(x) && !math.IsInf(x, 0) }
+// ms — время биржи в мс; 0 — биржа его не прислала.
+func ms(v int64) time.Time {
+	if v <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(v)
+}
+
+func msString(v string) time.Time {
+	n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	return ms(n)
+}
 
 func lastOrNil[T any](xs []T) *T {
 	if len(xs) == 0 {
@@ -76,19 +208,38 @@ func (r *HTTPRepo) FetchAllBooks(ctx context.Context, coin string, depth int) ([
 		b planner.Book
 		d string
 	}
-	ch := make(chan res, 7)
-
-	go func() { b, d := r.fetchBinance(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchOKX(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchBybit(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchKucoin(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchGate(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchHTX(ctx, coin, depth); ch <- res{b, d} }()
-	go func() { b, d := r.fetchBitget(ctx, coin, depth); ch <- res{b, d} }()
-
+	if depth <= 0 {
+		depth = r.depth
+	}
 	var books []planner.Book
 	var diags []string
-	for i := 0; i < 7; i++ {
+
+	ch := make(chan res, len(r.fetchers))
+	started := 0
+	for _, f := range r.fetchers {
+		f := f
+		// биржа с разомкнутым автоматом не опрашивается, чтобы не ждать её таймаут
+		if !f.breaker.Allow() {
+			diags = append(diags, f.name+":skipped:circuit-open")
+			continue
+		}
+		started++
+		go func() {
+			b, d := f.fetch(r, ctx, &f, coin, depth)
+			b.ReceivedAt = time.Now()
+			switch {
+			case !strings.HasPrefix(d, f.name+":err"):
+				f.breaker.Success()
+			case ctx.Err() != nil:
+				f.breaker.Release() // запрос отменил вызывающий — биржа не виновата
+			default:
+				f.breaker.Failure(d)
+			}
+			ch <- res{b, d}
+		}()
+	}
+
+	for i := 0; i < started; i++ {
 		r := <-ch
 		if (len(r.b.Asks) + len(r.b.Bids)) > 0 {
 			books = append(books, r.b)
@@ -100,310 +251,241 @@ func (r *HTTPRepo) FetchAllBooks(ctx context.Context, coin string, depth int) ([
 	return books, diags, nil
 }
 
+// Breakers — состояние автоматов по биржам в порядке опроса.
+func (r *HTTPRepo) Breakers() []breaker.Status {
+	out := make([]breaker.Status, 0, len(r.fetchers))
+	for _, f := range r.fetchers {
+		out = append(out, f.breaker.Status())
+	}
+	return out
+}
+
 // ====== Фетчеры бирж (<COIN>/USDT) ======
 
-// BINANCE
-func (r *HTTPRepo) fetchBinance(ctx context.Context, coin string, depth int) (planner.Book, string) {
+// BINANCE (времени в ответе нет — возраст считается по ReceivedAt)
+func (r *HTTPRepo) fetchBinance(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 5000 {
 		d = 5000
 	}
+	// у Binance вес запроса растёт с глубиной: у лимита глубину снижаем, а не ждём
+	requested := d
+	d = f.fitDepth(d)
 	symbol := strings.ToUpper(coin) + "USDT"
-	url := fmt.Sprintf("https://api.binance.com/api/v3/depth?limit=%d&symbol=%s", d, symbol)
+	url := fmt.Sprintf("%s/api/v3/depth?limit=%d&symbol=%s", f.baseURL, d, symbol)
 	var raw struct {
 		Asks [][]string `json:"asks"`
 		Bids [][]string `json:"bids"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil {
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
 		return planner.Book{Exchange: "binance"}, "binance:err:" + err.Error()
 	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
-	}
-	for _, it := range raw.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(raw.Asks, raw.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "binance"}, "binance:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "binance"}, "binance:empty"
 	}
+	if d < requested {
+		return planner.Book{Exchange: "binance", Asks: asks, Bids: bids}, fmt.Sprintf("binance:ok:depth-degraded=%d", d)
+	}
 	return planner.Book{Exchange: "binance", Asks: asks, Bids: bids}, "binance:ok"
 }
 
 // OKX
-func (r *HTTPRepo) fetchOKX(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchOKX(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 400 {
 		d = 400
 	}
 	inst := strings.ToUpper(coin) + "-USDT"
-	url := fmt.Sprintf("https://www.okx.com/api/v5/market/books?instId=%s&sz=%d", inst, d)
+	url := fmt.Sprintf("%s/api/v5/market/books?instId=%s&sz=%d", f.baseURL, inst, d)
 	var raw struct {
 		Code string `json:"code"`
 		Data []struct {
 			Asks [][]string `json:"asks"`
 			Bids [][]string `json:"bids"`
+			Ts   string     `json:"ts"` // мс строкой
 		} `json:"data"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil || raw.Code != "0" {
-		return planner.Book{Exchange: "okx"}, "okx:err"
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "okx"}, "okx:err:" + err.Error()
+	}
+	if raw.Code != "0" {
+		return planner.Book{Exchange: "okx"}, "okx:err:code=" + raw.Code
 	}
 	data := lastOrNil(raw.Data)
 	if data == nil {
 		return planner.Book{Exchange: "okx"}, "okx:empty"
 	}
-	var asks, bids []planner.Level
-	for _, it := range data.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
-	}
-	for _, it := range data.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(data.Asks, data.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "okx"}, "okx:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "okx"}, "okx:empty"
 	}
-	return planner.Book{Exchange: "okx", Asks: asks, Bids: bids}, "okx:ok"
+	return planner.Book{Exchange: "okx", Asks: asks, Bids: bids, Timestamp: msString(data.Ts)}, "okx:ok"
 }
 
 // BYBIT
-func (r *HTTPRepo) fetchBybit(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchBybit(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 200 {
 		d = 200
 	}
 	symbol := strings.ToUpper(coin) + "USDT"
-	url := fmt.Sprintf("https://api.bybit.com/v5/market/orderbook?category=spot&symbol=%s&limit=%d", symbol, d)
+	url := fmt.Sprintf("%s/v5/market/orderbook?category=spot&symbol=%s&limit=%d", f.baseURL, symbol, d)
 	var raw struct {
 		Result struct {
 			Asks [][]string `json:"a"`
 			Bids [][]string `json:"b"`
+			Ts   int64      `json:"ts"`
 		} `json:"result"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil {
-		return planner.Book{Exchange: "bybit"}, "bybit:err"
-	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Result.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "bybit"}, "bybit:err:" + err.Error()
 	}
-	for _, it := range raw.Result.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(raw.Result.Asks, raw.Result.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "bybit"}, "bybit:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "bybit"}, "bybit:empty"
 	}
-	return planner.Book{Exchange: "bybit", Asks: asks, Bids: bids}, "bybit:ok"
+	return planner.Book{Exchange: "bybit", Asks: asks, Bids: bids, Timestamp: ms(raw.Result.Ts)}, "bybit:ok"
 }
 
 // KUCOIN
-func (r *HTTPRepo) fetchKucoin(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchKucoin(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 200 {
 		d = 200
 	}
 	symbol := strings.ToUpper(coin) + "-USDT"
-	url := fmt.Sprintf("https://api.kucoin.com/api/v1/market/orderbook/level2_100?symbol=%s", symbol)
+	url := fmt.Sprintf("%s/api/v1/market/orderbook/level2_100?symbol=%s", f.baseURL, symbol)
 	var raw struct {
 		Code string `json:"code"`
 		Data struct {
 			Asks [][]string `json:"asks"`
 			Bids [][]string `json:"bids"`
+			Time int64      `json:"time"`
 		} `json:"data"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil || raw.Code != "200000" {
-		return planner.Book{Exchange: "kucoin"}, "kucoin:err"
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "kucoin"}, "kucoin:err:" + err.Error()
 	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Data.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
+	if raw.Code != "200000" {
+		return planner.Book{Exchange: "kucoin"}, "kucoin:err:code=" + raw.Code
 	}
-	for _, it := range raw.Data.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(raw.Data.Asks, raw.Data.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "kucoin"}, "kucoin:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "kucoin"}, "kucoin:empty"
 	}
-	return planner.Book{Exchange: "kucoin", Asks: asks, Bids: bids}, "kucoin:ok"
+	return planner.Book{Exchange: "kucoin", Asks: asks, Bids: bids, Timestamp: ms(raw.Data.Time)}, "kucoin:ok"
 }
 
 // GATE
-func (r *HTTPRepo) fetchGate(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchGate(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 200 {
 		d = 200
 	}
 	symbol := strings.ToUpper(coin) + "_USDT"
-	url := fmt.Sprintf("https://api.gateio.ws/api/v4/spot/order_book?currency_pair=%s&limit=%d", symbol, d)
+	url := fmt.Sprintf("%s/api/v4/spot/order_book?currency_pair=%s&limit=%d", f.baseURL, symbol, d)
 	var raw struct {
-		Asks [][]string `json:"asks"`
-		Bids [][]string `json:"bids"`
-	}
-	if err := r.doGET(ctx, url, &raw); err != nil {
-		return planner.Book{Exchange: "gate"}, "gate:err"
+		Asks    [][]string `json:"asks"`
+		Bids    [][]string `json:"bids"`
+		Current int64      `json:"current"` // мс снимка стакана (update — последнее изменение, у тихого рынка старое)
 	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "gate"}, "gate:err:" + err.Error()
 	}
-	for _, it := range raw.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(raw.Asks, raw.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "gate"}, "gate:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "gate"}, "gate:empty"
 	}
-	return planner.Book{Exchange: "gate", Asks: asks, Bids: bids}, "gate:ok"
+	// без current время стакана нулевое — planner возьмёт время получения
+	return planner.Book{Exchange: "gate", Asks: asks, Bids: bids, Timestamp: ms(raw.Current)}, "gate:ok"
 }
 
 // HTX (HUOBI)
-func (r *HTTPRepo) fetchHTX(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchHTX(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 200 {
 		d = 200
 	}
 	symbol := strings.ToLower(coin) + "usdt"
-	url := fmt.Sprintf("https://api.huobi.pro/market/depth?symbol=%s&type=step0", symbol)
+	url := fmt.Sprintf("%s/market/depth?symbol=%s&type=step0", f.baseURL, symbol)
 	var raw struct {
 		Tick struct {
 			Asks [][]float64 `json:"asks"`
 			Bids [][]float64 `json:"bids"`
+			Ts   int64       `json:"ts"`
 		} `json:"tick"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil {
-		return planner.Book{Exchange: "htx"}, "htx:err"
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "htx"}, "htx:err:" + err.Error()
 	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Tick.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		asks = append(asks, planner.Level{Price: it[0], Qty: it[1]})
+	asks, err := domain.OrdersFromFloats(raw.Tick.Asks)
+	if err != nil {
+		return planner.Book{Exchange: "htx"}, "htx:invalid:asks: " + err.Error()
 	}
-	for _, it := range raw.Tick.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		bids = append(bids, planner.Level{Price: it[0], Qty: it[1]})
+	bids, err := domain.OrdersFromFloats(raw.Tick.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "htx"}, "htx:invalid:bids: " + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "htx"}, "htx:empty"
 	}
-	return planner.Book{Exchange: "htx", Asks: asks, Bids: bids}, "htx:ok"
+	return planner.Book{Exchange: "htx", Asks: asks, Bids: bids, Timestamp: ms(raw.Tick.Ts)}, "htx:ok"
 }
 
 // BITGET
-func (r *HTTPRepo) fetchBitget(ctx context.Context, coin string, depth int) (planner.Book, string) {
+func (r *HTTPRepo) fetchBitget(ctx context.Context, f *fetcher, coin string, depth int) (planner.Book, string) {
 	d := depth
 	if d <= 0 || d > 200 {
 		d = 200
 	}
 	symbol := strings.ToUpper(coin) + "USDT"
-	url := fmt.Sprintf("https://api.bitget.com/api/spot/v1/market/depth?symbol=%s&type=step0&limit=%d", symbol, d)
+	url := fmt.Sprintf("%s/api/spot/v1/market/depth?symbol=%s&type=step0&limit=%d", f.baseURL, symbol, d)
 	var raw struct {
 		Data struct {
-			Asks [][]string `json:"asks"`
-			Bids [][]string `json:"bids"`
+			Asks      [][]string `json:"asks"`
+			Bids      [][]string `json:"bids"`
+			Timestamp string     `json:"timestamp"` // мс строкой
 		} `json:"data"`
 	}
-	if err := r.doGET(ctx, url, &raw); err != nil {
-		return planner.Book{Exchange: "bitget"}, "bitget:err"
+	if err := r.doGET(ctx, f, f.limit.cost(d), url, &raw); err != nil {
+		return planner.Book{Exchange: "bitget"}, "bitget:err:" + err.Error()
 	}
-	var asks, bids []planner.Level
-	for _, it := range raw.Data.Asks {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		asks = append(asks, planner.Level{Price: p, Qty: q})
-	}
-	for _, it := range raw.Data.Bids {
-		if len(it) < 2 {
-			continue
-		}
-		p, _ := strconv.ParseFloat(it[0], 64)
-		q, _ := strconv.ParseFloat(it[1], 64)
-		bids = append(bids, planner.Level{Price: p, Qty: q})
+	asks, bids, err := parseSides(raw.Data.Asks, raw.Data.Bids)
+	if err != nil {
+		return planner.Book{Exchange: "bitget"}, "bitget:invalid:" + err.Error()
 	}
 	asks = clampPositive(asks)
 	bids = clampPositive(bids)
-	sortAsks(asks)
-	sortBids(bids)
 	if len(asks) == 0 && len(bids) == 0 {
 		return planner.Book{Exchange: "bitget"}, "bitget:empty"
 	}
-	return planner.Book{Exchange: "bitget", Asks: asks, Bids: bids}, "bitget:ok"
+	return planner.Book{Exchange: "bitget", Asks: asks, Bids: bids, Timestamp: msString(raw.Data.Timestamp)}, "bitget:ok"
 }
@@ -0,0 +1,115 @@
+package exchangebooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serve поднимает фейковую биржу name, которая отвечает body на любой запрос.
+func serve(t *testing.T, name, body string) *HTTPRepo {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewHTTPRepoWith(Options{Exchanges: []string{name}, BaseURLs: map[string]string{name: srv.URL}})
+}
+
+func fetchOne(t *testing.T, r *HTTPRepo) ([]string, []float64, []float64) {
+	t.Helper()
+	books, diags, err := r.FetchAllBooks(context.Background(), "BTC", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 {
+		t.Fatalf("books = %d, diags = %v", len(books), diags)
+	}
+	var asks, bids []float64
+	for _, l := range books[0].Asks {
+		asks = append(asks, l.Price)
+	}
+	for _, l := range books[0].Bids {
+		bids = append(bids, l.Price)
+	}
+	return diags, asks, bids
+}
+
+// Лестница уходит в planner в порядке биржи: битый порядок ловит Sanity, а не чинит сортировка.
+func TestFetchKeepsLadderOrder(t *testing.T) {
+	r := serve(t, "okx", `{"code":"0","data":[{"asks":[["101","1"],["100","1"]],"bids":[["98","1"],["99","1"],["97","0"]],"ts":"1700000000000"}]}`)
+	_, asks, bids := fetchOne(t, r)
+	if len(asks) != 2 || asks[0] != 101 || asks[1] != 100 {
+		t.Errorf("asks = %v, want [101 100]", asks)
+	}
+	if len(bids) != 2 || bids[0] != 98 || bids[1] != 99 {
+		t.Errorf("bids = %v, want [98 99] (empty level dropped)", bids)
+	}
+}
+
+// Время стакана Gate — момент снимка (current), а не последнего изменения (update).
+func TestFetchGateTimestamp(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int64 // мс; 0 — биржа время не прислала
+	}{
+		{
+			name: "current",
+			body: `{"current":1700000005000,"update":1700000000000,"asks":[["100","1"]],"bids":[["99","1"]]}`,
+			want: 1700000005000,
+		},
+		{
+			name: "no current",
+			body: `{"update":1700000000000,"asks":[["100","1"]],"bids":[["99","1"]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books, _, err := serve(t, "gate", tt.body).FetchAllBooks(context.Background(), "BTC", 0)
+			if err != nil || len(books) != 1 {
+				t.Fatalf("books = %v, err = %v", books, err)
+			}
+			b := books[0]
+			if tt.want == 0 {
+				if !b.Timestamp.IsZero() {
+					t.Errorf("Timestamp = %v, want zero", b.Timestamp)
+				}
+			} else if got := b.Timestamp.UnixMilli(); got != tt.want {
+				t.Errorf("Timestamp = %d, want %d", got, tt.want)
+			}
+			if b.ReceivedAt.IsZero() {
+				t.Error("ReceivedAt is not set")
+			}
+		})
+	}
+}
+
+// Диагностика ошибки биржи несёт причину, а не голое "<биржа>:err".
+func TestFetchErrorDiagnostics(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string // префикс диагностики
+	}{
+		{name: "okx", body: `{"code":"51001","data":[]}`, want: "okx:err:code=51001"},
+		{name: "kucoin", body: `{"code":"400100"}`, want: "kucoin:err:code=400100"},
+	}
+	for _, ex := range []string{"binance", "okx", "bybit", "kucoin", "gate", "htx", "bitget"} {
+		tests = append(tests, struct{ name, body, want string }{name: ex, body: "not json", want: ex + ":err:"})
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, diags, err := serve(t, tt.name, tt.body).FetchAllBooks(context.Background(), "BTC", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diags) != 1 || !strings.HasPrefix(diags[0], tt.want) || strings.HasSuffix(diags[0], ":err:") {
+				t.Errorf("diags = %q, want %q with the cause", diags, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package exchangebooks
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// venueLimit — модель публичного лимита биржи по IP для запроса стакана.
+type venueLimit struct {
+	capacity float64       // вес в окне
+	window   time.Duration // за окно бакет пополняется полностью
+	cost     func(depth int) float64
+	depths   []int // до каких глубин можно снизить запрос, по убыванию; nil — только ждать
+
+	usedHeader   string // заголовок с использованным весом окна
+	remainHeader string // или с остатком
+}
+
+// limits — лимиты с запасом относительно опубликованных биржами.
+var limits = map[string]venueLimit{
+	// 6000 веса в минуту, вес /api/v3/depth зависит от limit
+	"binance": {capacity: 6000, window: time.Minute, cost: binanceDepthWeight,
+		depths: []int{5000, 1000, 500, 100}, usedHeader: "X-MBX-USED-WEIGHT-1M"},
+	// 40 запросов за 2 секунды
+	"okx": {capacity: 40, window: 2 * time.Second, cost: perRequest(1)},
+	// 600 запросов за 5 секунд
+	"bybit": {capacity: 600, window: 5 * time.Second, cost: perRequest(1)},
+	// публичный пул 2000 веса за 30 секунд, level2_100 весит 2
+	"kucoin": {capacity: 2000, window: 30 * time.Second, cost: perRequest(2), remainHeader: "Gw-Ratelimit-Remaining"},
+	// 200 запросов за 10 секунд на эндпоинт
+	"gate": {capacity: 200, window: 10 * time.Second, cost: perRequest(1), remainHeader: "X-Gate-RateLimit-Requests-Remain"},
+	// лимит не публикуется явно — консервативно
+	"htx": {capacity: 10, window: time.Second, cost: perRequest(1)},
+	// 20 запросов в секунду
+	"bitget": {capacity: 20, window: time.Second, cost: perRequest(1)},
+}
+
+// limitHeadroom — доля ёмкости, которую не тратим на полную глубину: оставляем её
+// параллельным запросам, чтобы не упереться в бан.
+const limitHeadroom = 0.1
+
+func perRequest(w float64) func(int) float64 {
+	return func(int) float64 { return w }
+}
+
+func binanceDepthWeight(limit int) float64 {
+	switch {
+	case limit <= 100:
+		return 5
+	case limit <= 500:
+		return 25
+	case limit <= 1000:
+		return 50
+	default:
+		return 250
+	}
+}
+
+// fitDepth — глубина, на которую сейчас хватает веса с учётом запаса. Если не хватает
+// даже на минимальную, возвращается минимальная: запрос подождёт в очереди бакета.
+func (f *fetcher) fitDepth(depth int) int {
+	avail := f.bucket.Available() - limitHeadroom*f.bucket.Capacity()
+	if f.limit.cost(depth) <= avail {
+		return depth
+	}
+	for _, d := range f.limit.depths {
+		if d < depth && f.limit.cost(d) <= avail {
+			return d
+		}
+	}
+	if n := len(f.limit.depths); n > 0 && f.limit.depths[n-1] < depth {
+		return f.limit.depths[n-1]
+	}
+	return depth
+}
+
+// observe сверяет бакет с заголовками лимита биржи; 429/418 обнуляет остаток.
+func (f *fetcher) observe(res *http.Response) {
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusTeapot {
+		f.bucket.Sync(0)
+		return
+	}
+	if used, ok := headerFloat(res.Header, f.limit.usedHeader); ok {
+		f.bucket.Sync(f.bucket.Capacity() - used)
+	}
+	if remain, ok := headerFloat(res.Header, f.limit.remainHeader); ok {
+		f.bucket.Sync(remain)
+	}
+}
+
+func headerFloat(h http.Header, key string) (float64, bool) {
+	if key == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(h.Get(key)), 64)
+	return v, err == nil
+}
+
+// RateLimited — биржи, у которых бакет опустился ниже запаса: недавно был 429/418
+// или вес почти выбран. Фоновые задачи по ним сбавляют темп.
+func (r *HTTPRepo) RateLimited() []string {
+	var out []string
+	for _, f := range r.fetchers {
+		if f.bucket.Available() < limitHeadroom*f.bucket.Capacity() {
+			out = append(out, f.name)
+		}
+	}
+	return out
+}
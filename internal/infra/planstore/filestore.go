@@ -0,0 +1,197 @@
+package planstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+// FileStore реализует planner.PlanStore: append-only JSONL-файл + индекс в памяти.
+// Одна строка файла — один planner.StoredPlan.
+type FileStore struct {
+	mu    sync.RWMutex
+	path  string
+	f     *os.File
+	plans []planner.StoredPlan // в порядке записи (старые первыми)
+	byID  map[string]int
+}
+
+// Open открывает (или создаёт) файл истории и загружает его в память.
+func Open(path string) (*FileStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("planstore: mkdir: %w", err)
+		}
+	}
+	s := &FileStore{path: path, byID: map[string]int{}}
+	tail, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("planstore: open: %w", err)
+	}
+	// иначе следующая запись приклеится к оборванной строке и тоже пропадёт
+	if err := tail.repair(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("planstore: repair %s: %w", path, err)
+	}
+	s.f = f
+	return s, nil
+}
+
+// tornTail — последняя строка файла без перевода строки: запись оборвалась (сбой посреди Save).
+type tornTail struct {
+	at   int64 // смещение начала строки
+	keep bool  // строка читается целиком — не хватает только перевода строки
+}
+
+// repair дописывает перевод строки к целой записи или отрезает оборванную.
+func (t *tornTail) repair(f *os.File) error {
+	if t == nil {
+		return nil
+	}
+	if t.keep {
+		_, err := f.Write([]byte{'\n'})
+		return err
+	}
+	return f.Truncate(t.at)
+}
+
+// load читает историю в память. Битые строки пропускаются с записью в лог;
+// оборванный хвост возвращается, чтобы Open его починил.
+func (s *FileStore) load() (*tornTail, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("planstore: open: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for line := 1; ; line++ {
+		raw, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("planstore: read %s:%d: %w", s.path, line, err)
+		}
+		if len(raw) == 0 {
+			return nil, nil
+		}
+		at := offset
+		offset += int64(len(raw))
+		torn := raw[len(raw)-1] != '\n'
+
+		ok := true
+		if trimmed := strings.TrimSpace(string(raw)); trimmed != "" {
+			var p planner.StoredPlan
+			if err := json.Unmarshal([]byte(trimmed), &p); err != nil {
+				ok = false
+				if torn {
+					log.Printf("planstore: %s:%d: truncating torn last line: %v", s.path, line, err)
+				} else {
+					log.Printf("planstore: %s:%d: skipping broken line: %v", s.path, line, err)
+				}
+			} else {
+				s.byID[p.ID] = len(s.plans)
+				s.plans = append(s.plans, p)
+			}
+		}
+		if torn {
+			return &tornTail{at: at, keep: ok}, nil
+		}
+	}
+}
+
+// Close закрывает файл истории.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *FileStore) Save(_ context.Context, p planner.StoredPlan) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return fmt.Errorf("planstore: closed")
+	}
+	if _, err := s.f.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("planstore: write: %w", err)
+	}
+	s.byID[p.ID] = len(s.plans)
+	s.plans = append(s.plans, p)
+	return nil
+}
+
+func (s *FileStore) Get(_ context.Context, id string) (planner.StoredPlan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.byID[strings.TrimSpace(id)]
+	if !ok {
+		return planner.StoredPlan{}, planner.ErrPlanNotFound
+	}
+	return s.plans[i], nil
+}
+
+func (s *FileStore) List(_ context.Context, f planner.PlanFilter) ([]planner.StoredPlan, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []planner.StoredPlan
+	for i := len(s.plans) - 1; i >= 0; i-- {
+		p := s.plans[i]
+		if f.Base != "" && !strings.EqualFold(p.Request.Base, f.Base) {
+			continue
+		}
+		if f.Quote != "" && !strings.EqualFold(p.Request.Quote, f.Quote) {
+			continue
+		}
+		if f.Scenario != "" && !strings.EqualFold(p.Result.Scenario, f.Scenario) {
+			continue
+		}
+		if !f.From.IsZero() && p.CreatedAt.Before(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && !p.CreatedAt.Before(f.To) {
+			continue
+		}
+		out = append(out, p)
+	}
+	// файл append-only, но на всякий случай упорядочим по времени
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+
+	total := len(out)
+	if f.Offset > 0 {
+		if f.Offset >= len(out) {
+			return nil, total, nil
+		}
+		out = out[f.Offset:]
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, total, nil
+}
@@ -0,0 +1,157 @@
+package planstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func plan(id, base, quote, sc string, at time.Time) planner.StoredPlan {
+	return planner.StoredPlan{
+		ID:        id,
+		CreatedAt: at,
+		Request:   planner.Request{Base: base, Quote: quote, Amount: 100, Scenario: sc},
+		Result:    planner.Result{ID: id, Scenario: sc, Base: base, Quote: quote},
+	}
+}
+
+func open(t *testing.T, path string) *FileStore {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func save(t *testing.T, s *FileStore, ps ...planner.StoredPlan) {
+	t.Helper()
+	for _, p := range ps {
+		if err := s.Save(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func ids(ps []planner.StoredPlan) []string {
+	out := make([]string, 0, len(ps))
+	for _, p := range ps {
+		out = append(out, p.ID)
+	}
+	return out
+}
+
+func TestSaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "plans.jsonl")
+	s := open(t, path)
+	save(t, s, plan("p1", "BTC", "USDT", "optimal", t0), plan("p2", "ETH", "USDT", "best_single", t0.Add(time.Minute)))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(context.Background(), plan("p3", "BTC", "USDT", "optimal", t0)); err == nil {
+		t.Error("Save after Close succeeded")
+	}
+
+	s = open(t, path)
+	got, err := s.Get(context.Background(), " p2 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Request.Base != "ETH" || got.Result.Scenario != "best_single" || !got.CreatedAt.Equal(t0.Add(time.Minute)) {
+		t.Errorf("reloaded plan = %+v", got)
+	}
+	if _, err := s.Get(context.Background(), "nope"); !errors.Is(err, planner.ErrPlanNotFound) {
+		t.Errorf("Get(nope) err = %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := open(t, filepath.Join(t.TempDir(), "plans.jsonl"))
+	save(t, s,
+		plan("p1", "BTC", "USDT", "optimal", t0),
+		plan("p2", "ETH", "USDT", "optimal", t0.Add(1*time.Hour)),
+		plan("p3", "BTC", "USDT", "best_single", t0.Add(2*time.Hour)),
+		plan("p4", "BTC", "ETH", "optimal", t0.Add(3*time.Hour)),
+		plan("p5", "btc", "usdt", "optimal", t0.Add(4*time.Hour)),
+	)
+	tests := []struct {
+		name      string
+		filter    planner.PlanFilter
+		want      []string
+		wantTotal int
+	}{
+		{name: "all, newest first", want: []string{"p5", "p4", "p3", "p2", "p1"}, wantTotal: 5},
+		{name: "base and quote ignore case", filter: planner.PlanFilter{Base: "BTC", Quote: "usdt"}, want: []string{"p5", "p3", "p1"}, wantTotal: 3},
+		{name: "scenario", filter: planner.PlanFilter{Scenario: "best_single"}, want: []string{"p3"}, wantTotal: 1},
+		{name: "from inclusive, to exclusive", filter: planner.PlanFilter{From: t0.Add(time.Hour), To: t0.Add(3 * time.Hour)}, want: []string{"p3", "p2"}, wantTotal: 2},
+		{name: "page", filter: planner.PlanFilter{Offset: 1, Limit: 2}, want: []string{"p4", "p3"}, wantTotal: 5},
+		{name: "offset past end", filter: planner.PlanFilter{Offset: 9}, want: []string{}, wantTotal: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := s.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			if g := ids(got); !slices.Equal(g, tt.want) {
+				t.Errorf("ids = %v, want %v", g, tt.want)
+			}
+		})
+	}
+}
+
+// Сбой посреди записи: битая строка в середине пропускается, оборванный хвост
+// отрезается, и следующий план не приклеивается к нему — переживает перезапуск.
+func TestOpenRepairsTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+		want []string
+	}{
+		{name: "torn json", tail: `{"ID":"p3","Crea`, want: []string{"p4", "p1"}},
+		{name: "whole record without newline", tail: `{"ID":"p3"}`, want: []string{"p4", "p1", "p3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plans.jsonl")
+			s := open(t, path)
+			save(t, s, plan("p1", "BTC", "USDT", "optimal", t0))
+			_ = s.Close()
+
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = f.WriteString("not json\n" + tt.tail)
+			_ = f.Close()
+
+			s = open(t, path)
+			save(t, s, plan("p4", "BTC", "USDT", "optimal", t0.Add(time.Hour)))
+			_ = s.Close()
+
+			s = open(t, path)
+			got, _, err := s.List(context.Background(), planner.PlanFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g := ids(got); !slices.Equal(g, tt.want) {
+				t.Errorf("ids after reopen = %v, want %v", g, tt.want)
+			}
+			if _, err := s.Get(context.Background(), "p4"); err != nil {
+				t.Errorf("plan saved after the crash is lost: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State — состояние автомата.
+type State string
+
+const (
+	Closed   State = "closed"    // запросы идут как обычно
+	Open     State = "open"      // площадка пропускается до истечения паузы
+	HalfOpen State = "half-open" // пропускается один пробный запрос
+)
+
+// Status — снимок состояния для отчётов.
+type Status struct {
+	Name      string
+	State     State
+	Failures  int // подряд
+	LastError string
+	OpenUntil time.Time // только для Open
+}
+
+// Breaker размыкается после threshold неудач подряд, через cooldown пропускает
+// один пробный запрос: успех замыкает цепь, неудача размыкает её снова.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	lastErr  string
+	openedAt time.Time
+	probing  bool // пробный запрос в полёте
+}
+
+func New(name string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown, state: Closed}
+}
+
+// Allow — можно ли сейчас идти на площадку. После true вызывающий обязан
+// сообщить итог через Success, Failure или Release.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if time.Now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = HalfOpen
+		fallthrough
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state, b.failures, b.lastErr, b.probing = Closed, 0, "", false
+}
+
+func (b *Breaker) Failure(err string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastErr = err
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+	b.probing = false
+}
+
+// Release — запрос не дал ответа по вине вызывающего (например, отменён контекст):
+// состояние не меняется, пробный слот освобождается.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := Status{Name: b.name, State: b.state, Failures: b.failures, LastError: b.lastErr}
+	if b.state == Open {
+		st.OpenUntil = b.openedAt.Add(b.cooldown)
+	}
+	return st
+}
@@ -0,0 +1,66 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := New("binance", 2, time.Hour)
+	for i := 0; i < 2; i++ {
+		if !b.Allow() {
+			t.Fatalf("attempt %d rejected while closed", i+1)
+		}
+		b.Failure("timeout")
+	}
+	st := b.Status()
+	if st.State != Open || st.Failures != 2 || st.LastError != "timeout" || st.OpenUntil.IsZero() {
+		t.Fatalf("status = %+v", st)
+	}
+	if b.Allow() {
+		t.Error("open breaker allowed a request before cooldown")
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(b *Breaker)
+		want   State
+	}{
+		{name: "success closes", finish: func(b *Breaker) { b.Success() }, want: Closed},
+		{name: "failure reopens", finish: func(b *Breaker) { b.Failure("503") }, want: Open},
+		{name: "release keeps half-open", finish: func(b *Breaker) { b.Release() }, want: HalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New("okx", 1, 0) // без паузы: следующий Allow — сразу пробный
+			b.Allow()
+			b.Failure("timeout")
+
+			if !b.Allow() {
+				t.Fatal("probe rejected after cooldown")
+			}
+			if b.Allow() {
+				t.Fatal("second request allowed while probe is in flight")
+			}
+			tt.finish(b)
+			if got := b.Status().State; got != tt.want {
+				t.Errorf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := New("gate", 2, time.Hour)
+	b.Allow()
+	b.Failure("timeout")
+	b.Allow()
+	b.Success()
+	b.Allow()
+	b.Failure("timeout")
+	if st := b.Status(); st.State != Closed || st.Failures != 1 {
+		t.Errorf("status = %+v, want closed with 1 failure", st)
+	}
+}
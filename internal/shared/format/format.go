@@ -3,11 +3,25 @@ package format
 import (
 	"fmt"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
 // FloatRU возвращает строку в формате "100.000.000,00"
 func FloatRU(v float64, decimals int) string {
-	s := fmt.Sprintf("%.*f", decimals, v) // "100000000.00"
+	return groupRU(fmt.Sprintf("%.*f", decimals, v), decimals) // "100000000.00"
+}
+
+// DecimalRU — то же для десятичного числа, без перевода во float64.
+func DecimalRU(v decimal.Decimal, decimals int32) string {
+	return groupRU(v.StringFixed(decimals), int(decimals))
+}
+
+func groupRU(s string, decimals int) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
 	parts := strings.SplitN(s, ".", 2)
 	intPart := parts[0]
 	frac := ""
@@ -27,7 +41,7 @@ func FloatRU(v float64, decimals int) string {
 		out[i], out[j] = out[j], out[i]
 	}
 	if decimals == 0 {
-		return string(out)
+		return sign + string(out)
 	}
-	return string(out) + "," + frac
+	return sign + string(out) + "," + frac
 }
@@ -0,0 +1,75 @@
+// Package money — точность сумм и курсов по активам поверх десятичного типа
+// shopspring/decimal: суммы складываются без ошибок двоичной плавающей точки,
+// а округляются один раз, на границе ответа.
+package money
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DivScale — знаков после запятой при делении внутри расчёта (доли монеты, средние цены).
+	DivScale int32 = 18
+
+	// rateDigits — сколько значащих цифр оставлять в курсе.
+	rateDigits = 8
+	// minRatePlaces — курс к USDT не грубее центов.
+	minRatePlaces = 2
+)
+
+// Precision — знаков после запятой для сумм в активе: USDT — центы, монеты — 8 знаков.
+func Precision(asset string) int32 {
+	if strings.EqualFold(strings.TrimSpace(asset), "USDT") {
+		return 2
+	}
+	return 8
+}
+
+// Unit — единица точности актива: 0.01 USDT, 0.00000001 монеты.
+func Unit(asset string) decimal.Decimal { return decimal.New(1, -Precision(asset)) }
+
+// Amount округляет сумму в активе asset до его точности.
+func Amount(v decimal.Decimal, asset string) decimal.Decimal {
+	return v.Round(Precision(asset))
+}
+
+// RatePlaces — знаков после запятой, при которых в курсе v остаётся rateDigits
+// значащих цифр (но не меньше minRatePlaces): кросс-курс 0.0342 не превращается в 0.03.
+func RatePlaces(v decimal.Decimal) int32 {
+	if v.IsZero() {
+		return minRatePlaces
+	}
+	intDigits := int32(v.NumDigits()) + v.Exponent() // цифр до запятой; <= 0 — ведущие нули после неё
+	return max(rateDigits-intDigits, minRatePlaces)
+}
+
+// Rate округляет курс до RatePlaces.
+func Rate(v decimal.Decimal) decimal.Decimal {
+	return v.Round(RatePlaces(v))
+}
+
+// Div — a/b с точностью DivScale; 0 при b == 0.
+func Div(a, b decimal.Decimal) decimal.Decimal {
+	if b.IsZero() {
+		return decimal.Zero
+	}
+	return a.DivRound(b, DivScale)
+}
+
+// FromFloat — число из стакана или запроса как десятичное. Берётся кратчайшая
+// запись float64, так что цена "0.1" из стакана остаётся ровно 0.1.
+func FromFloat(f float64) decimal.Decimal { return decimal.NewFromFloat(f) }
+
+// Number — десятичное число ответа, которое в JSON пишется числом, а не строкой:
+// UI, вывод CLI и история планов читают суммы как числа. Глобальный
+// decimal.MarshalJSONWithoutQuotes не трогаем — он действует на весь процесс.
+// Читается и число, и строка.
+type Number struct{ decimal.Decimal }
+
+// Num оборачивает десятичное в Number.
+func Num(d decimal.Decimal) Number { return Number{Decimal: d} }
+
+// MarshalJSON пишет число без кавычек.
+func (n Number) MarshalJSON() ([]byte, error) { return []byte(n.String()), nil }
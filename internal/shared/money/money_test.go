@@ -0,0 +1,94 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		v, asset, want string
+	}{
+		{"1234.565", "USDT", "1234.57"},
+		{"1234.564", "usdt", "1234.56"},
+		{"0.123456785", "BTC", "0.12345679"},
+		{"0.000000004", "ETH", "0"},
+	}
+	for _, tt := range tests {
+		if got := Amount(d(tt.v), tt.asset); !got.Equal(d(tt.want)) {
+			t.Errorf("Amount(%s, %s) = %s, want %s", tt.v, tt.asset, got, tt.want)
+		}
+	}
+	if got := Unit("USDT"); !got.Equal(d("0.01")) {
+		t.Errorf("Unit(USDT) = %s", got)
+	}
+	if got := Unit("BTC"); !got.Equal(d("0.00000001")) {
+		t.Errorf("Unit(BTC) = %s", got)
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		v, want string
+	}{
+		{"100000.456", "100000.46"},        // к USDT — не грубее центов
+		{"3000.123456", "3000.1235"},       // 8 значащих цифр
+		{"0.0299882356789", "0.029988236"}, // кросс-курс не схлопывается до 0.03
+		{"0.000012345678912", "0.000012345679"},
+		{"0", "0"},
+	}
+	for _, tt := range tests {
+		if got := Rate(d(tt.v)); !got.Equal(d(tt.want)) {
+			t.Errorf("Rate(%s) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	if got := Div(d("1"), d("3")); !got.Equal(d("0.333333333333333333")) {
+		t.Errorf("Div(1, 3) = %s", got)
+	}
+	if got := Div(d("1"), decimal.Zero); !got.IsZero() {
+		t.Errorf("Div(1, 0) = %s, want 0", got)
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	if got := FromFloat(0.1); got.String() != "0.1" {
+		t.Errorf("FromFloat(0.1) = %s", got)
+	}
+}
+
+func TestNumberJSON(t *testing.T) {
+	type resp struct {
+		Price Number          `json:"price"`
+		Risk  Number          `json:"risk,omitzero"`
+		Raw   decimal.Decimal `json:"raw"`
+	}
+	raw, err := json.Marshal(resp{Price: Num(d("100000.46")), Raw: d("0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Number — числом, прочие decimal — как по умолчанию в shopspring/decimal, строкой
+	if got, want := string(raw), `{"price":100000.46,"raw":"0.1"}`; got != want {
+		t.Errorf("json = %s, want %s", got, want)
+	}
+	if decimal.MarshalJSONWithoutQuotes {
+		t.Error("decimal.MarshalJSONWithoutQuotes включён глобально")
+	}
+
+	// разбирается и число, и строка
+	for _, in := range []string{`{"price":0.029988236}`, `{"price":"0.029988236"}`} {
+		var r resp
+		if err := json.Unmarshal([]byte(in), &r); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !r.Price.Equal(d("0.029988236")) {
+			t.Errorf("%s: price = %s", in, r.Price)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket — token bucket в единицах веса запроса: вмещает capacity,
+// за window пополняется с нуля до полного. Безопасен для конкурентного использования.
+type Bucket struct {
+	capacity float64
+	rate     float64 // вес в секунду
+
+	mu     sync.Mutex
+	tokens float64 // может уйти в минус: это вес, зарезервированный ожидающими
+	last   time.Time
+}
+
+func NewBucket(capacity float64, window time.Duration) *Bucket {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &Bucket{
+		capacity: capacity,
+		rate:     capacity / window.Seconds(),
+		tokens:   capacity,
+		last:     time.Now(),
+	}
+}
+
+func (b *Bucket) Capacity() float64 { return b.capacity }
+
+// Available — сколько веса можно потратить прямо сейчас без ожидания.
+func (b *Bucket) Available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	return max(b.tokens, 0)
+}
+
+// Wait резервирует weight и ждёт, пока бакет его покроет; запросы обслуживаются
+// в порядке вызова. При отмене ctx резерв возвращается.
+func (b *Bucket) Wait(ctx context.Context, weight float64) error {
+	weight = min(weight, b.capacity) // иначе не дождаться никогда
+
+	b.mu.Lock()
+	b.refill(time.Now())
+	b.tokens -= weight
+	deficit := -b.tokens
+	b.mu.Unlock()
+	if deficit <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.refund(weight)
+		return ctx.Err()
+	}
+}
+
+// Sync подстраивает бакет под остаток, который сообщила биржа: на тот же IP
+// могут ходить и другие клиенты, так что верим меньшему из двух.
+func (b *Bucket) Sync(remaining float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	if remaining < b.tokens {
+		b.tokens = max(remaining, 0)
+	}
+}
+
+// refund возвращает резерв отменённого ожидания; к этому моменту бакет мог
+// пополниться сам, так что больше capacity не набирается.
+func (b *Bucket) refund(weight float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	b.tokens = min(b.tokens+weight, b.capacity)
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if dt := now.Sub(b.last).Seconds(); dt > 0 {
+		b.tokens = min(b.tokens+dt*b.rate, b.capacity)
+	}
+	b.last = now
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucketWait(t *testing.T) {
+	b := NewBucket(10, 100*time.Millisecond) // 100 веса в секунду
+	ctx := context.Background()
+
+	start := time.Now()
+	if err := b.Wait(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 20*time.Millisecond {
+		t.Errorf("full bucket waited %s", waited)
+	}
+	if got := b.Available(); got > 1 {
+		t.Errorf("available after draining = %v", got)
+	}
+
+	start = time.Now()
+	if err := b.Wait(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("empty bucket waited %s, want about 50ms", waited)
+	}
+}
+
+func TestBucketWaitCanceled(t *testing.T) {
+	b := NewBucket(10, time.Hour)
+	ctx := context.Background()
+	if err := b.Wait(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx, 4); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	// резерв отменённого ожидания вернулся: следующий запрос не ждёт за него
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < 0 {
+		t.Errorf("tokens = %v after canceled wait, reservation leaked", tokens)
+	}
+}
+
+// Резерв возвращается поверх пополнения, но бакет не переполняется.
+func TestBucketRefundCapped(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens float64
+		weight float64
+		want   float64
+	}{
+		{name: "reservation only", tokens: -4, weight: 4, want: 0},
+		{name: "partly refilled", tokens: 3, weight: 4, want: 7},
+		{name: "refilled meanwhile", tokens: 8, weight: 5, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBucket(10, time.Hour)
+			b.tokens = tt.tokens
+			b.refund(tt.weight)
+			if math.Abs(b.tokens-tt.want) > 0.01 {
+				t.Errorf("tokens = %v, want %v", b.tokens, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketSync(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining float64
+		want      float64
+	}{
+		{name: "exchange reports less", remaining: 3, want: 3},
+		{name: "exchange reports more", remaining: 50, want: 10},
+		{name: "negative", remaining: -1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBucket(10, time.Hour)
+			b.Sync(tt.remaining)
+			if got := b.Available(); got < tt.want-0.01 || got > tt.want+0.01 {
+				t.Errorf("available = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketOversizedWeight(t *testing.T) {
+	b := NewBucket(5, 50*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// вес больше ёмкости урезается до неё, иначе ожидание не кончится никогда
+	if err := b.Wait(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPError — ответ биржи с кодом не 2xx.
+type HTTPError struct {
+	Status     int
+	Text       string        // resp.Status, например "429 Too Many Requests"
+	RetryAfter time.Duration // сколько просит подождать биржа; 0 — не сказала
+}
+
+func (e *HTTPError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("HTTP %s, retry after %s", e.Text, e.RetryAfter.Round(time.Millisecond))
+	}
+	return "HTTP " + e.Text
+}
+
+// RateLimited — 429 или бан по IP (418 у Binance).
+func (e *HTTPError) RateLimited() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status == http.StatusTeapot
+}
+
+// CheckResponse возвращает *HTTPError для кода не 2xx, иначе nil.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	text := resp.Status
+	if text == "" {
+		text = strconv.Itoa(resp.StatusCode)
+	}
+	e := &HTTPError{Status: resp.StatusCode, Text: text}
+	if e.RateLimited() || resp.StatusCode == http.StatusServiceUnavailable {
+		e.RetryAfter = retryAfterHeader(resp.Header, time.Now())
+	}
+	return e
+}
+
+// retryAfterHeader читает паузу из стандартного Retry-After и заголовков лимитов бирж:
+//
+//	Retry-After                        — секунды или HTTP-дата (Binance и др.)
+//	X-Bapi-Limit-Reset-Timestamp       — Bybit, unix ms сброса окна
+//	X-Gate-RateLimit-Reset-Timestamp   — Gate, unix ms сброса окна
+//	Gw-Ratelimit-Reset                 — KuCoin, мс до сброса окна
+func retryAfterHeader(h http.Header, now time.Time) time.Duration {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			return t.Sub(now)
+		}
+	}
+	for _, key := range []string{"X-Bapi-Limit-Reset-Timestamp", "X-Gate-RateLimit-Reset-Timestamp"} {
+		if ms, err := strconv.ParseInt(strings.TrimSpace(h.Get(key)), 10, 64); err == nil && ms > 0 {
+			if t := time.UnixMilli(ms); t.After(now) {
+				return t.Sub(now)
+			}
+		}
+	}
+	if ms, err := strconv.ParseInt(strings.TrimSpace(h.Get("Gw-Ratelimit-Reset")), 10, 64); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return 0
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неповторяемую (неверный символ, ошибка API и т.п.).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Retryable — имеет ли смысл повторять запрос: сетевые ошибки, 408, 429/418 и 5xx — да,
+// прочие 4xx и ошибки, помеченные Permanent, — нет.
+func Retryable(err error) bool {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.RateLimited() || he.Status == http.StatusRequestTimeout || he.Status >= 500
+	}
+	return true
+}
+
+// RetryAfter — пауза, которую запросила биржа, если она есть в ошибке.
+func RetryAfter(err error) (time.Duration, bool) {
+	var he *HTTPError
+	if errors.As(err, &he) && he.RetryAfter > 0 {
+		return he.RetryAfter, true
+	}
+	return 0, false
+}
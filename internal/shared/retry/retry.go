@@ -1,19 +1,88 @@
 package retry
 
-import "time"
-
-// WithRetry выполняет op с повторами и простым экспоненциальным бэкоффом.
-func WithRetry(attempts int, sleep time.Duration, op func() error) error {
-	var err error
-	backoff := sleep
-	for i := 0; i < attempts; i++ {
-		if err = op(); err == nil {
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy — настройки повторов; нулевые поля — умолчания.
+type Policy struct {
+	Attempts   int           // всего попыток, включая первую
+	Backoff    time.Duration // пауза перед второй попыткой, дальше удваивается
+	MaxBackoff time.Duration // потолок экспоненциальной паузы (по умолчанию 5s)
+	Jitter     float64       // случайный разброс паузы ±доля, 0..1 (по умолчанию 0.2)
+	MaxWait    time.Duration // дольше этого Retry-After не ждём (по умолчанию 10s)
+}
+
+const (
+	defaultMaxBackoff = 5 * time.Second
+	defaultJitter     = 0.2
+	defaultMaxWait    = 10 * time.Second
+)
+
+// Do выполняет op, пока она не вернёт nil, постоянную ошибку или не кончатся попытки.
+// Между попытками ждёт бэкофф с разбросом либо Retry-After биржи, если он дольше;
+// ожидание прерывается отменой ctx. После последней попытки не спит.
+func Do(ctx context.Context, p Policy, op func(ctx context.Context) error) error {
+	attempts := p.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := p.Backoff
+	for i := 0; ; i++ {
+		err := op(ctx)
+		if err == nil {
 			return nil
 		}
-		time.Sleep(backoff)
-		if backoff < 5*time.Second {
-			backoff *= 2
+		if i == attempts-1 || !Retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.jitter(backoff)
+		if ra, ok := RetryAfter(err); ok {
+			if ra > p.maxWait() {
+				return err // бан надолго — ждать бессмысленно, время уже есть в тексте ошибки
+			}
+			wait = max(wait, ra)
+		}
+		// не успеем повторить до дедлайна — не ждём зря
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
 		}
+		backoff = min(backoff*2, p.maxBackoff())
+	}
+}
+
+func (p Policy) jitter(d time.Duration) time.Duration {
+	j := p.Jitter
+	if j <= 0 {
+		j = defaultJitter
+	}
+	if j > 1 {
+		j = 1
+	}
+	return time.Duration(float64(d) * (1 - j + 2*j*rand.Float64()))
+}
+
+func (p Policy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return defaultMaxBackoff
+}
+
+func (p Policy) maxWait() time.Duration {
+	if p.MaxWait > 0 {
+		return p.MaxWait
 	}
-	return err
+	return defaultMaxWait
 }
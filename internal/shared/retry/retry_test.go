@@ -0,0 +1,100 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var errNet = errors.New("connection reset")
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error // ответы op по порядку; дальше — nil
+		attempts  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success first try", attempts: 3, wantCalls: 1},
+		{name: "retries network error", errs: []error{errNet, errNet}, attempts: 3, wantCalls: 3},
+		{name: "gives up after attempts", errs: []error{errNet, errNet, errNet}, attempts: 2, wantCalls: 2, wantErr: true},
+		{name: "permanent stops", errs: []error{Permanent(errNet)}, attempts: 3, wantCalls: 1, wantErr: true},
+		{name: "4xx stops", errs: []error{&HTTPError{Status: 400, Text: "400 Bad Request"}}, attempts: 3, wantCalls: 1, wantErr: true},
+		{name: "5xx retries", errs: []error{&HTTPError{Status: 502, Text: "502 Bad Gateway"}}, attempts: 3, wantCalls: 2},
+		{name: "long ban not awaited", errs: []error{&HTTPError{Status: 418, Text: "418", RetryAfter: time.Hour}}, attempts: 3, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), Policy{Attempts: tt.attempts, Backoff: time.Millisecond}, func(context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
+
+func TestDoHonorsRetryAfter(t *testing.T) {
+	start, calls := time.Now(), 0
+	err := Do(context.Background(), Policy{Attempts: 2, Backoff: time.Millisecond}, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return &HTTPError{Status: 429, Text: "429", RetryAfter: 50 * time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("waited %s, want at least Retry-After", waited)
+	}
+}
+
+func TestDoStopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls := 0
+	err := Do(ctx, Policy{Attempts: 5, Backoff: time.Second}, func(context.Context) error {
+		calls++
+		return errNet
+	})
+	if !errors.Is(err, errNet) || calls != 1 {
+		t.Errorf("err = %v, calls = %d: backoff past deadline must not be awaited", err, calls)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "seconds", header: http.Header{"Retry-After": {"7"}}, want: 7 * time.Second},
+		{name: "http date", header: http.Header{"Retry-After": {now.Add(30 * time.Second).Format(http.TimeFormat)}}, want: 30 * time.Second},
+		{name: "bybit reset", header: http.Header{"X-Bapi-Limit-Reset-Timestamp": {"1767323047000"}}, want: 2 * time.Second},
+		{name: "kucoin reset", header: http.Header{"Gw-Ratelimit-Reset": {"1500"}}, want: 1500 * time.Millisecond},
+		{name: "reset in the past", header: http.Header{"X-Gate-RateLimit-Reset-Timestamp": {"1767323000000"}}, want: 0},
+		{name: "none", header: http.Header{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfterHeader(tt.header, now); got != tt.want {
+				t.Errorf("retryAfterHeader = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/format"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/export"
+	"cryptobot/internal/usecase/orderbook"
+	"cryptobot/internal/usecase/planner"
+)
+
+// Коды выхода неинтерактивного режима.
+const (
+	ExitOK    = 0
+	ExitError = 1 // ошибка расчёта или сети
+	ExitUsage = 2 // неверные аргументы
+)
+
+// Planner — методы planner.Service, которые использует CLI.
+type Planner interface {
+	Plan(ctx context.Context, in planner.Request) (planner.Result, error)
+	Compare(ctx context.Context, in planner.Request) (planner.CompareResult, error)
+	Book(ctx context.Context, in planner.BookRequest) (planner.BookResult, error)
+}
+
+// DefaultTimeout — таймаут команды по умолчанию (флаг --timeout).
+var DefaultTimeout = 15 * time.Second
+
+// Commands — имена подкоманд неинтерактивного режима.
+var Commands = []string{"plan", "compare", "book", "symbols"}
+
+// IsCommand — является ли аргумент подкомандой.
+func IsCommand(arg string) bool {
+	for _, c := range Commands {
+		if arg == c {
+			return true
+		}
+	}
+	return false
+}
+
+// errUsage — ошибка аргументов (код выхода ExitUsage).
+var errUsage = errors.New("usage")
+
+// RunCommand выполняет подкоманду и возвращает код выхода.
+//
+//	plan    --base BTC --quote USDT --amount 100000 [--scenario optimal] [--exchanges binance,okx] [-o table|json|csv] [--export file]
+//	compare --base BTC --quote USDT --amount 100000 [--exchanges ...] [-o ...] [--export file]
+//	book    --coin ETH [--tick 1 | --bps 5] [--bands 0.5,1,2] [--limit 20] [--exchanges ...] [-o ...]
+//	symbols [-o ...]
+func RunCommand(svc Planner, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		_, _ = fmt.Fprintf(stderr, "usage: app <%s> [flags]\n", strings.Join(Commands, "|"))
+		return ExitUsage
+	}
+	cmd := args[0]
+
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	base := fs.String("base", "", "что получаем (BASE)")
+	quote := fs.String("quote", "USDT", "чем платим (QUOTE)")
+	amount := fs.Float64("amount", 0, "сколько платим, в QUOTE")
+	sc := fs.String("scenario", "", strings.Join(planner.Scenarios(), " | ")+" (по умолчанию — из конфига)")
+	exchanges := fs.String("exchanges", "", "биржи через запятую (по умолчанию все)")
+	slippage := fs.Float64("slippage-bps", 0, "min_venues: допуск к VWAP optimal, б.п. (по умолчанию — из конфига)")
+	coin := fs.String("coin", "", "монета для book (<COIN>/USDT)")
+	tick := fs.Float64("tick", 0, "book: группировка по шагу цены, USDT")
+	bps := fs.Float64("bps", 0, "book: группировка по шагу в б.п.")
+	bands := fs.String("bands", "", "book: полосы глубины ±% через запятую")
+	limit := fs.Int("limit", 20, "book: строк на сторону (0 — все)")
+	output := fs.String("o", "table", "формат вывода: table | json | csv")
+	fs.StringVar(output, "output", "table", "то же, что -o")
+	exportPath := fs.String("export", "", "plan/compare: сохранить в файл (.csv, .xlsx, .pdf)")
+	timeout := fs.Duration("timeout", DefaultTimeout, "таймаут запроса к биржам")
+	if err := fs.Parse(args[1:]); err != nil {
+		return ExitUsage
+	}
+
+	out := strings.ToLower(strings.TrimSpace(*output))
+	if out != "table" && out != "json" && out != "csv" {
+		_, _ = fmt.Fprintf(stderr, "unknown output %q (table, json, csv)\n", *output)
+		return ExitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	req := planner.Request{
+		Base:      strings.ToUpper(strings.TrimSpace(*base)),
+		Quote:     strings.ToUpper(strings.TrimSpace(*quote)),
+		Amount:    *amount,
+		Scenario:  *sc,
+		Exchanges: splitList(*exchanges),
+
+		SlippageBps: *slippage,
+	}
+
+	var err error
+	switch cmd {
+	case "plan":
+		err = runPlan(ctx, svc, req, out, *exportPath, stdout)
+	case "compare":
+		err = runCompare(ctx, svc, req, out, *exportPath, stdout)
+	case "book":
+		br := planner.BookRequest{
+			Coin:      strings.ToUpper(strings.TrimSpace(*coin)),
+			Tick:      *tick,
+			Bps:       *bps,
+			Limit:     *limit,
+			Exchanges: req.Exchanges,
+		}
+		if br.Coin == "" {
+			br.Coin = req.Base // допускаем --base вместо --coin
+		}
+		for _, b := range splitList(*bands) {
+			v, perr := strconv.ParseFloat(strings.ReplaceAll(b, ",", "."), 64)
+			if perr != nil || v <= 0 {
+				err = fmt.Errorf("%w: invalid band %q", errUsage, b)
+				break
+			}
+			br.Bands = append(br.Bands, v)
+		}
+		if err == nil {
+			err = runBook(ctx, svc, br, out, stdout)
+		}
+	case "symbols":
+		err = runSymbols(out, stdout)
+	}
+
+	if errors.Is(err, errUsage) || errors.Is(err, planner.ErrUnknownScenario) {
+		_, _ = fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Ошибка выполнения: %v\n", err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+func validatePair(req planner.Request) error {
+	if req.Base == "" || req.Quote == "" {
+		return fmt.Errorf("%w: --base and --quote are required", errUsage)
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("%w: --amount must be > 0", errUsage)
+	}
+	return nil
+}
+
+func runPlan(ctx context.Context, svc Planner, req planner.Request, out, exportPath string, w io.Writer) error {
+	if err := validatePair(req); err != nil {
+		return err
+	}
+	res, err := svc.Plan(ctx, req)
+	if err != nil {
+		return err
+	}
+	if exportPath != "" {
+		if err := WritePlanFile(exportPath, res); err != nil {
+			return err
+		}
+	}
+	switch out {
+	case "json":
+		return writeJSON(w, res)
+	case "csv":
+		return export.Plan(w, export.CSV, res)
+	}
+	printPlanTable(w, res)
+	return nil
+}
+
+func runCompare(ctx context.Context, svc Planner, req planner.Request, out, exportPath string, w io.Writer) error {
+	if err := validatePair(req); err != nil {
+		return err
+	}
+	res, err := svc.Compare(ctx, req)
+	if err != nil {
+		return err
+	}
+	if exportPath != "" {
+		if err := WriteCompareFile(exportPath, res); err != nil {
+			return err
+		}
+	}
+	switch out {
+	case "json":
+		return writeJSON(w, res)
+	case "csv":
+		return export.Compare(w, export.CSV, res)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(w, "=== Сравнение сценариев %s/%s (%s) ===\n", res.Base, res.Quote, res.GeneratedAt)
+	_, _ = fmt.Fprintln(tw, "#\tСценарий\tVWAP\tПотрачено\tПолучено\tВыгода\tб.п.\t")
+	for _, it := range res.Items {
+		if it.Error != "" {
+			_, _ = fmt.Fprintf(tw, "-\t%s\t%s\t\t\t\t\t\n", it.Scenario, it.Error)
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s %s\t%s %s\t%s %s\t%.1f\t\n",
+			it.Rank, it.Scenario,
+			rateRU(it.VWAP.Decimal),
+			amountRU(it.TotalCost.Decimal, res.Quote), res.Quote,
+			amountRU(it.Generated.Decimal, res.Base), res.Base,
+			amountRU(it.SavingsAbs.Decimal, it.SavingsUnit), it.SavingsUnit,
+			it.SavingsBps)
+	}
+	_ = tw.Flush()
+	printDiagnostics(w, res.Diagnostics)
+	return nil
+}
+
+func runBook(ctx context.Context, svc Planner, req planner.BookRequest, out string, w io.Writer) error {
+	if req.Coin == "" {
+		return fmt.Errorf("%w: --coin is required", errUsage)
+	}
+	res, err := svc.Book(ctx, req)
+	if err != nil {
+		return err
+	}
+	switch out {
+	case "json":
+		return writeJSON(w, res)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Comma = ';'
+		_ = cw.Write([]string{"side", "price", "qty", "notional", "cumQty", "cumNotional"})
+		for _, r := range res.Asks {
+			_ = cw.Write(append([]string{"ask"}, ladderCells(r)...))
+		}
+		for _, r := range res.Bids {
+			_ = cw.Write(append([]string{"bid"}, ladderCells(r)...))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	_, _ = fmt.Fprintf(w, "=== Сводный стакан %s/USDT (%s) ===\n", res.Coin, res.GeneratedAt)
+	_, _ = fmt.Fprintf(w, "Биржи: %s\nbestBid=%s  bestAsk=%s  mid=%s\n\n", strings.Join(res.Exchanges, ", "),
+		format.FloatRU(res.BestBid, 2), format.FloatRU(res.BestAsk, 2), format.FloatRU(res.Mid, 2))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "Сторона\tЦена\tКол-во\tUSDT\tНакоплено\tНакоплено USDT\t")
+	for i := len(res.Asks) - 1; i >= 0; i-- {
+		_, _ = fmt.Fprintf(tw, "ask\t%s\t\n", strings.Join(ladderCells(res.Asks[i]), "\t"))
+	}
+	for _, r := range res.Bids {
+		_, _ = fmt.Fprintf(tw, "bid\t%s\t\n", strings.Join(ladderCells(r), "\t"))
+	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprintln(w, "\nГлубина от mid:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "±%\tAsk qty\tAsk USDT\tBid qty\tBid USDT\t")
+	for _, d := range res.Depth {
+		_, _ = fmt.Fprintf(tw, "%g\t%s\t%s\t%s\t%s\t\n", d.Pct,
+			format.FloatRU(d.AskQty, 4), format.FloatRU(d.AskNotional, 2),
+			format.FloatRU(d.BidQty, 4), format.FloatRU(d.BidNotional, 2))
+	}
+	_ = tw.Flush()
+	printDiagnostics(w, res.Diagnostics)
+	return nil
+}
+
+func runSymbols(out string, w io.Writer) error {
+	all := append([]string{"USDT"}, Coins...)
+	switch out {
+	case "json":
+		return writeJSON(w, map[string][]string{"bases": all, "quotes": all})
+	}
+	for _, c := range all {
+		_, _ = fmt.Fprintln(w, c)
+	}
+	return nil
+}
+
+func printPlanTable(w io.Writer, res planner.Result) {
+	_, _ = fmt.Fprintf(w, "=== План %s/%s — %s (%s) ===\n", res.Base, res.Quote, res.Scenario, res.GeneratedAt)
+	_, _ = fmt.Fprintf(w, "VWAP:        %s\n", rateRU(res.VWAP.Decimal))
+	if res.RiskAdjustedVWAP.IsPositive() {
+		_, _ = fmt.Fprintf(w, "VWAP с риском: %s\n", rateRU(res.RiskAdjustedVWAP.Decimal))
+	}
+	_, _ = fmt.Fprintf(w, "Потрачено:   %s %s\n", amountRU(res.TotalCost.Decimal, res.Quote), res.Quote)
+	_, _ = fmt.Fprintf(w, "Получено:    %s %s\n", amountRU(res.Generated.Decimal, res.Base), res.Base)
+	if res.Unspent.IsPositive() {
+		_, _ = fmt.Fprintf(w, "Остаток:     %s %s\n", amountRU(res.Unspent.Decimal, res.Quote), res.Quote)
+	}
+	if res.ID != "" {
+		_, _ = fmt.Fprintf(w, "ID плана:    %s\n", res.ID)
+	}
+	if res.Confidence != "" {
+		_, _ = fmt.Fprintf(w, "Уверенность: %s\n", res.Confidence)
+	}
+	for _, v := range res.Venues {
+		_, _ = fmt.Fprintf(w, "Биржи %s:   %s (допуск %s б.п., против optimal: %s USDT, %s б.п.)\n", v.Coin,
+			strings.Join(v.Exchanges, ", "), format.FloatRU(v.ToleranceBps, 1),
+			amountRU(v.CostDiffUSDT.Decimal, "USDT"), format.FloatRU(v.CostDiffBps, 2))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(tw, "Биржа\tКол-во\tЦена\tСумма USDT\tГлубина %\t")
+	legUnit := res.Base // при продаже за USDT ножки в QUOTE
+	if strings.EqualFold(res.Base, "USDT") {
+		legUnit = res.Quote
+	}
+	for _, l := range res.Legs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", l.Exchange,
+			amountRU(l.Amount.Decimal, legUnit), rateRU(l.Price.Decimal), amountRU(l.Amount.Mul(l.Price.Decimal), "USDT"), depthMark(l))
+	}
+	_ = tw.Flush()
+	printDiagnostics(w, res.Diagnostics)
+}
+
+// depthMark — расход видимой глубины ножки: «!» — стакан выбран целиком, «*» — дошли до последнего уровня.
+func depthMark(l planner.Leg) string {
+	s := format.FloatRU(l.DepthUsedPct, 0)
+	switch {
+	case l.Exhausted:
+		s += "!"
+	case l.LastLevel:
+		s += "*"
+	}
+	return s
+}
+
+func printDiagnostics(w io.Writer, diags []string) {
+	if len(diags) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\nДиагностика: %s\n", strings.Join(diags, ", "))
+}
+
+func ladderCells(r orderbook.LadderRow) []string {
+	return []string{
+		format.FloatRU(r.Price, 2), format.FloatRU(r.Qty, 6), format.FloatRU(r.Notional, 2),
+		format.FloatRU(r.CumQty, 6), format.FloatRU(r.CumNotional, 2),
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// amountRU — сумма в активе с его точностью; rateRU — курс со значащими цифрами.
+func amountRU(v decimal.Decimal, asset string) string {
+	return format.DecimalRU(v, money.Precision(asset))
+}
+
+func rateRU(v decimal.Decimal) string { return format.DecimalRU(v, money.RatePlaces(v)) }
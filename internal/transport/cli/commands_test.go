@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+// noBooks — репозиторий, до которого команда дойти не должна.
+type noBooks struct{ t *testing.T }
+
+func (r noBooks) FetchAllBooks(context.Context, string, int) ([]planner.Book, []string, error) {
+	r.t.Error("books fetched for an invalid request")
+	return nil, nil, nil
+}
+
+func TestRunCommandUnknownScenario(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunCommand(planner.New(noBooks{t}), []string{"plan", "--base", "BTC", "--amount", "100", "--scenario", "cheapest"}, &stdout, &stderr)
+	if code != ExitUsage {
+		t.Errorf("exit code = %d, want %d (stderr %q)", code, ExitUsage, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "optimal") {
+		t.Errorf("stderr = %q, want the supported scenarios", stderr.String())
+	}
+}
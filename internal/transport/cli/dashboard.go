@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/usecase/orderbook"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// ANSI-последовательности для полноэкранного режима.
+const (
+	ansiAltScreenOn  = "\x1b[?1049h\x1b[?25l" // альтернативный экран + скрыть курсор
+	ansiAltScreenOff = "\x1b[?25h\x1b[?1049l"
+	ansiHome         = "\x1b[H\x1b[2J"
+	ansiReset        = "\x1b[0m"
+	ansiGreen        = "\x1b[32m"
+	ansiRed          = "\x1b[31m"
+	ansiYellow       = "\x1b[33m"
+	ansiBold         = "\x1b[1m"
+)
+
+// dashboardDepthPct — полоса глубины от mid, которую показывает dashboard.
+const dashboardDepthPct = 1.0
+
+// DashboardFrame — данные одного обновления режима наблюдения.
+type DashboardFrame struct {
+	Title     string
+	UpdatedAt time.Time
+	Interval  time.Duration
+	Books     []*domain.OrderBook // по биржам и символам в порядке показа
+	Errors    []string
+
+	Direction scenario.Direction         // для одноэтапной сделки
+	Results   map[string]scenario.Result // одноэтапная сделка: сценарий → результат
+
+	From, To string         // обмен монета → монета
+	Route    []RouteSummary // заполняется вместо Results
+}
+
+// Dashboard — полноэкранный режим наблюдения поверх CLIPresenter:
+// кадр собирается целиком и перерисовывается, изменения с прошлого кадра подсвечиваются.
+type Dashboard struct {
+	out  io.Writer
+	prev map[string]float64 // значения прошлого кадра для подсветки
+}
+
+func NewDashboard(w io.Writer) *Dashboard {
+	return &Dashboard{out: w, prev: map[string]float64{}}
+}
+
+// Start переключает терминал на альтернативный экран.
+func (d *Dashboard) Start() { _, _ = fmt.Fprint(d.out, ansiAltScreenOn) }
+
+// Stop возвращает обычный экран.
+func (d *Dashboard) Stop() { _, _ = fmt.Fprint(d.out, ansiAltScreenOff) }
+
+// Render перерисовывает экран.
+func (d *Dashboard) Render(f DashboardFrame) {
+	var buf bytes.Buffer
+	pr := &CLIPresenter{out: &buf}
+	next := map[string]float64{}
+
+	buf.WriteString(ansiHome)
+	fmt.Fprintf(&buf, "%s=== %s ===%s\n", ansiBold, f.Title, ansiReset)
+	fmt.Fprintf(&buf, "Обновлено: %s, каждые %s. Ctrl+C — выход.\n\n", f.UpdatedAt.Format("15:04:05"), f.Interval)
+
+	for _, ob := range f.Books {
+		top := bookTop(ob)
+		key := ob.Exchange + ":" + ob.Symbol
+		next[key+":bid"], next[key+":ask"] = top.bid, top.ask
+
+		// строка стакана подсвечивается, если сдвинулся лучший bid/ask
+		color := ""
+		if d.changed(key+":bid", top.bid) || d.changed(key+":ask", top.ask) {
+			color = ansiYellow
+		}
+		buf.WriteString(color)
+		pr.ShowOrderBookSummary(ob)
+		if color != "" {
+			buf.WriteString(ansiReset)
+		}
+		fmt.Fprintf(&buf, "          spread=%.2f б.п.  depth ±%g%%: bid %.2f / ask %.2f USDT\n",
+			top.spreadBps(), dashboardDepthPct, top.bidDepth, top.askDepth)
+	}
+	for _, e := range f.Errors {
+		fmt.Fprintf(&buf, "%s%s%s\n", ansiRed, e, ansiReset)
+	}
+
+	switch {
+	case f.Route != nil:
+		pr.RenderRouteComparisons(f.From, f.To, f.Route)
+		var changes []string
+		for _, r := range f.Route {
+			received := r.Received.InexactFloat64() // для стрелок изменений точности float64 достаточно
+			next["route:"+r.Name] = received
+			if line, ok := d.delta("route:"+r.Name, r.Name, received, true); ok {
+				changes = append(changes, line)
+			}
+		}
+		writeChanges(&buf, changes)
+	case f.Results != nil:
+		pr.RenderComparisons(f.Results)
+		names := make([]string, 0, len(f.Results))
+		for name := range f.Results {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var changes []string
+		for _, name := range names {
+			vwap := f.Results[name].AveragePrice.InexactFloat64()
+			next["vwap:"+name] = vwap
+			// для покупки рост VWAP — хуже, для продажи — лучше
+			if line, ok := d.delta("vwap:"+name, name, vwap, f.Direction == scenario.Sell); ok {
+				changes = append(changes, line)
+			}
+		}
+		writeChanges(&buf, changes)
+	}
+
+	d.prev = next
+	_, _ = d.out.Write(buf.Bytes())
+}
+
+func (d *Dashboard) changed(key string, v float64) bool {
+	old, ok := d.prev[key]
+	return ok && old != v
+}
+
+// delta — строка изменения значения с прошлого кадра: зелёная, если стало лучше, красная — если хуже.
+func (d *Dashboard) delta(key, name string, v float64, higherIsBetter bool) (string, bool) {
+	old, ok := d.prev[key]
+	if !ok || old == v || old == 0 {
+		return "", false
+	}
+	pct := (v - old) / old * 100
+	color := ansiRed
+	if (v > old) == higherIsBetter {
+		color = ansiGreen
+	}
+	return fmt.Sprintf("%s  %s: %.8f → %.8f (%+.3f%%)%s", color, name, old, v, pct, ansiReset), true
+}
+
+func writeChanges(buf *bytes.Buffer, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	buf.WriteString("\nИзменения с прошлого обновления:\n")
+	for _, l := range lines {
+		buf.WriteString(l + "\n")
+	}
+}
+
+// topOfBook — лучшие цены и глубина одного стакана.
+type topOfBook struct {
+	bid, ask           float64
+	bidDepth, askDepth float64 // USDT в полосе ±dashboardDepthPct от mid
+}
+
+func (t topOfBook) spreadBps() float64 {
+	mid := (t.bid + t.ask) / 2
+	if t.bid <= 0 || t.ask <= 0 || mid <= 0 {
+		return 0
+	}
+	return (t.ask - t.bid) / mid * 10000
+}
+
+func bookTop(ob *domain.OrderBook) topOfBook {
+	books := map[string]*domain.OrderBook{ob.Exchange: ob}
+	asks := orderbook.CombinedAsks(books)
+	bids := orderbook.CombinedBids(books)
+
+	var t topOfBook
+	if len(asks) > 0 {
+		t.ask = asks[0].Price
+	}
+	if len(bids) > 0 {
+		t.bid = bids[0].Price
+	}
+	if bands := orderbook.DepthBands(asks, bids, orderbook.Mid(asks, bids), []float64{dashboardDepthPct}); len(bands) == 1 {
+		t.bidDepth, t.askDepth = bands[0].BidNotional, bands[0].AskNotional
+	}
+	return t
+}
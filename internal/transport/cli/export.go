@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"cryptobot/internal/usecase/export"
+	"cryptobot/internal/usecase/planner"
+)
+
+// PlanRequest переводит ответы интерактивного опроса в запрос планировщика.
+func (p InputParams) PlanRequest() planner.Request {
+	switch p.Action {
+	case "sell":
+		// продаём монету за USDT: платим монетой, получаем USDT
+		return planner.Request{Base: "USDT", Quote: p.LeftCoinName, Amount: p.LeftCoinVolume}
+	case "swap":
+		// монета → монета: платим левой, получаем правую (маршрут через USDT)
+		return planner.Request{Base: p.RightCoinName, Quote: p.LeftCoinName, Amount: p.LeftCoinVolume}
+	}
+	return planner.Request{Base: p.RightCoinName, Quote: "USDT", Amount: p.LeftCoinVolume}
+}
+
+// WritePlanFile сохраняет план одного сценария; формат берётся из расширения (.csv, .xlsx, .pdf).
+func WritePlanFile(path string, res planner.Result) error {
+	return writeExportFile(path, func(w io.Writer, f export.Format) error { return export.Plan(w, f, res) })
+}
+
+// WriteCompareFile сохраняет сравнение сценариев; формат берётся из расширения (.csv, .xlsx, .pdf).
+func WriteCompareFile(path string, res planner.CompareResult) error {
+	return writeExportFile(path, func(w io.Writer, f export.Format) error { return export.Compare(w, f, res) })
+}
+
+func writeExportFile(path string, write func(io.Writer, export.Format) error) error {
+	f, err := export.ParseFormat(filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if err := write(out, f); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("export: %w", err)
+	}
+	return out.Close()
+}
@@ -11,23 +11,27 @@ import (
 
 // InputParams — параметры, собранные интерактивно в CLI.
 type InputParams struct {
-	Action         string
+	Action         string // buy | sell | swap (монета → USDT → монета)
 	LeftCoinName   string
 	LeftCoinVolume float64
 	RightCoinName  string
 }
 
+// Coins — монеты, которые предлагаются в CLI (котируются к USDT на всех биржах).
+var Coins = []string{"BTC", "ETH", "BNB", "SOL", "XRP", "ADA", "DOGE", "TON", "TRX", "DOT"}
+
 // GetInteractiveParams — опрос пользователя в терминале.
 func GetInteractiveParams() InputParams {
 	reader := bufio.NewReader(os.Stdin)
 
 	action := askAction(reader)
 
-	coins := []string{"BTC", "ETH", "BNB", "SOL", "XRP", "ADA", "DOGE", "TON", "TRX", "DOT"}
+	coins := Coins
 
 	params := InputParams{Action: action}
 
-	if action == "buy" {
+	switch action {
+	case "buy":
 		params.LeftCoinName = "USDT"
 
 		fmt.Println("\nНа какую монету хотите обменять USDT?")
@@ -38,7 +42,7 @@ func GetInteractiveParams() InputParams {
 		fmt.Printf("\nОбмениваем USDT на %s\nДоступно USDT: %s\n",
 			params.RightCoinName, format.FloatRU(params.LeftCoinVolume, 2))
 
-	} else {
+	case "sell":
 		params.RightCoinName = "USDT"
 
 		fmt.Println("\nКакую монету хотите продать за USDT?")
@@ -49,6 +53,26 @@ func GetInteractiveParams() InputParams {
 
 		fmt.Printf("\nПродаём %s за USDT\nДоступно %s: %s\n",
 			params.LeftCoinName, params.LeftCoinName, format.FloatRU(params.LeftCoinVolume, 8))
+
+	case "swap":
+		fmt.Println("\nКакую монету отдаёте?")
+		params.LeftCoinName = askFromList(reader, coins, 1)
+
+		prompt := fmt.Sprintf("\nСколько у вас %s? (Enter = 1.0): ", params.LeftCoinName)
+		params.LeftCoinVolume = askFloat(reader, prompt, 1.0)
+
+		// вторая монета — из оставшихся, одинаковые выбирать нельзя
+		rest := make([]string, 0, len(coins)-1)
+		for _, c := range coins {
+			if c != params.LeftCoinName {
+				rest = append(rest, c)
+			}
+		}
+		fmt.Printf("\nНа какую монету обменять %s?\n", params.LeftCoinName)
+		params.RightCoinName = askFromList(reader, rest, 1)
+
+		fmt.Printf("\nОбмениваем %s на %s через USDT\nДоступно %s: %s\n",
+			params.LeftCoinName, params.RightCoinName, params.LeftCoinName, format.FloatRU(params.LeftCoinVolume, 8))
 	}
 
 	return params
@@ -59,7 +83,8 @@ func askAction(r *bufio.Reader) string {
 		fmt.Println("Выберите действие:")
 		fmt.Println("1) Купить монету за USDT")
 		fmt.Println("2) Продать монету за USDT")
-		fmt.Print("Ваш выбор [1-2] (Enter = 1): ")
+		fmt.Println("3) Обменять монету на монету (через USDT)")
+		fmt.Print("Ваш выбор [1-3] (Enter = 1): ")
 
 		raw, _ := r.ReadString('\n')
 		raw = strings.TrimSpace(raw)
@@ -69,8 +94,10 @@ func askAction(r *bufio.Reader) string {
 			return "buy"
 		case "2":
 			return "sell"
+		case "3":
+			return "swap"
 		default:
-			fmt.Println("Введите 1 или 2, либо нажмите Enter для значения по умолчанию.")
+			fmt.Println("Введите 1, 2 или 3, либо нажмите Enter для значения по умолчанию.")
 		}
 	}
 }
@@ -2,20 +2,32 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strconv"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
 	"cryptobot/internal/usecase/scenario"
 )
 
-type CLIPresenter struct{}
+// cent — остаток меньше цента не показываем: это пыль от округления частичного уровня.
+var cent = decimal.New(1, -2)
+
+type CLIPresenter struct {
+	out io.Writer
+}
 
-func NewCLIPresenter() *CLIPresenter { return &CLIPresenter{} }
+func NewCLIPresenter() *CLIPresenter { return &CLIPresenter{out: os.Stdout} }
 
-func (c *CLIPresenter) Infof(format string, args ...any)  { fmt.Printf(format, args...) }
-func (c *CLIPresenter) Warnf(format string, args ...any)  { fmt.Printf(format, args...) }
-func (c *CLIPresenter) Errorf(format string, args ...any) { fmt.Printf(format, args...) }
+func (c *CLIPresenter) Infof(format string, args ...any)  { fmt.Fprintf(c.out, format, args...) }
+func (c *CLIPresenter) Warnf(format string, args ...any)  { fmt.Fprintf(c.out, format, args...) }
+func (c *CLIPresenter) Errorf(format string, args ...any) { fmt.Fprintf(c.out, format, args...) }
 
 // Короткое резюме по стакану конкретной биржи (топ-уровни ask/bid)
 func (c *CLIPresenter) ShowOrderBookSummary(ob *domain.OrderBook) {
@@ -24,10 +36,10 @@ func (c *CLIPresenter) ShowOrderBookSummary(ob *domain.OrderBook) {
 	}
 	var bestAsk, bestBid string
 	if len(ob.Asks) > 0 {
-		bestAsk = ob.Asks[0].Price
+		bestAsk = strconv.FormatFloat(ob.Asks[0].Price, 'f', -1, 64)
 	}
 	if len(ob.Bids) > 0 {
-		bestBid = ob.Bids[0].Price
+		bestBid = strconv.FormatFloat(ob.Bids[0].Price, 'f', -1, 64)
 	}
 	// timestamp может быть в секундах или миллисекундах
 	var ts time.Time
@@ -36,32 +48,32 @@ func (c *CLIPresenter) ShowOrderBookSummary(ob *domain.OrderBook) {
 	} else {
 		ts = time.Unix(ob.Timestamp, 0)
 	}
-	fmt.Printf("  %-7s %-12s  bestBid=%-12s  bestAsk=%-12s  @%s\n",
+	fmt.Fprintf(c.out, "  %-7s %-12s  bestBid=%-12s  bestAsk=%-12s  @%s\n",
 		ob.Exchange, ob.Symbol, bestBid, bestAsk, ts.Format("15:04:05"))
 }
 
 // Печать результатов одного сценария
 func (c *CLIPresenter) RenderScenario(title string, r scenario.Result) {
-	fmt.Printf("\n== %s ==\n", title)
-	if len(r.Legs) == 0 || r.TotalQty == 0 || r.AveragePrice == 0 {
-		fmt.Println("Нет данных для отображения.")
+	fmt.Fprintf(c.out, "\n== %s ==\n", title)
+	if len(r.Legs) == 0 || r.TotalQty.IsZero() || r.AveragePrice.IsZero() {
+		fmt.Fprintln(c.out, "Нет данных для отображения.")
 		return
 	}
 
-	fmt.Printf("Asset: %s\n", r.Asset)
-	fmt.Printf("VWAP:  %.8f\n", r.AveragePrice)
-	fmt.Printf("Итого монет: %.8f\n", r.TotalQty)
-	fmt.Printf("Итого USDT:  %.2f\n", r.TotalUSDT)
-	if r.Leftover > 0 {
-		fmt.Printf("Не израсходовано (из-за глубины): %.2f USDT\n", r.Leftover)
+	fmt.Fprintf(c.out, "Asset: %s\n", r.Asset)
+	fmt.Fprintf(c.out, "VWAP:  %s\n", r.AveragePrice.StringFixed(8))
+	fmt.Fprintf(c.out, "Итого монет: %s\n", r.TotalQty.StringFixed(8))
+	fmt.Fprintf(c.out, "Итого USDT:  %s\n", r.TotalUSDT.StringFixed(2))
+	if r.Leftover.GreaterThanOrEqual(cent) {
+		fmt.Fprintf(c.out, "Не израсходовано (из-за глубины): %s USDT\n", r.Leftover.StringFixed(2))
 	}
 
 	// Агрегируем по бирже — чтобы в Legs не было «шума» из множества дробных ног
 	type aggRow struct {
 		ex   string
-		qty  float64
-		usdt float64
-		avg  float64
+		qty  decimal.Decimal
+		usdt decimal.Decimal
+		avg  decimal.Decimal
 	}
 	agg := map[string]*aggRow{}
 	for _, l := range r.Legs {
@@ -70,23 +82,21 @@ func (c *CLIPresenter) RenderScenario(title string, r scenario.Result) {
 			row = &aggRow{ex: l.Exchange}
 			agg[l.Exchange] = row
 		}
-		row.qty += l.Qty
-		row.usdt += l.AmountUSDT
+		row.qty = row.qty.Add(l.Qty)
+		row.usdt = row.usdt.Add(l.AmountUSDT)
 	}
 
 	rows := make([]aggRow, 0, len(agg))
 	for _, v := range agg {
-		if v.qty > 0 {
-			v.avg = v.usdt / v.qty
-		}
+		v.avg = money.Div(v.usdt, v.qty)
 		rows = append(rows, *v)
 	}
-	sort.Slice(rows, func(i, j int) bool { return rows[i].usdt > rows[j].usdt })
+	sort.Slice(rows, func(i, j int) bool { return rows[i].usdt.GreaterThan(rows[j].usdt) })
 
-	fmt.Println("\nБиржа            Кол-во (qty)        Цена (avg)        Сумма (USDT)")
-	fmt.Println("---------------------------------------------------------------------")
+	fmt.Fprintln(c.out, "\nБиржа            Кол-во (qty)        Цена (avg)        Сумма (USDT)")
+	fmt.Fprintln(c.out, "---------------------------------------------------------------------")
 	for _, x := range rows {
-		fmt.Printf("%-16s %-18.8f %-16.8f %.2f\n", x.ex, x.qty, x.avg, x.usdt)
+		fmt.Fprintf(c.out, "%-16s %-18s %-16s %s\n", x.ex, x.qty.StringFixed(8), x.avg.StringFixed(8), x.usdt.StringFixed(2))
 	}
 }
 
@@ -94,20 +104,102 @@ func (c *CLIPresenter) RenderScenario(title string, r scenario.Result) {
 func (c *CLIPresenter) RenderComparisons(results map[string]scenario.Result) {
 	type row struct {
 		name string
-		vwap float64
-		qty  float64
-		usdt float64
+		vwap decimal.Decimal
+		qty  decimal.Decimal
+		usdt decimal.Decimal
 	}
 	var xs []row
 	for name, r := range results {
 		xs = append(xs, row{name: name, vwap: r.AveragePrice, qty: r.TotalQty, usdt: r.TotalUSDT})
 	}
-	sort.Slice(xs, func(i, j int) bool { return xs[i].vwap < xs[j].vwap })
+	sort.Slice(xs, func(i, j int) bool { return xs[i].vwap.LessThan(xs[j].vwap) })
+
+	fmt.Fprintln(c.out, "\n=== Сравнение сценариев ===")
+	fmt.Fprintln(c.out, "Сценарий                         VWAP              Qty              USDT")
+	fmt.Fprintln(c.out, "--------------------------------------------------------------------------")
+	for _, x := range xs {
+		fmt.Fprintf(c.out, "%-30s %-17s %-16s %s\n", x.name, x.vwap.StringFixed(8), x.qty.StringFixed(8), x.usdt.StringFixed(2))
+	}
+}
+
+// RouteSummary — итог обмена монета → USDT → монета по одному сценарию.
+type RouteSummary struct {
+	Name     string
+	Sold     decimal.Decimal // продано исходной монеты
+	Unsold   decimal.Decimal // не удалось продать (не хватило глубины)
+	USDT     decimal.Decimal // выручка первого этапа = бюджет второго
+	Received decimal.Decimal // получено целевой монеты
+	Leftover decimal.Decimal // USDT, не потраченные на втором этапе
+}
+
+// RouteSummaries — строки сравнения маршрута из результата planner.Service.Compare
+// (сценарии без плана пропускаются).
+func RouteSummaries(res planner.CompareResult) []RouteSummary {
+	rows := make([]RouteSummary, 0, len(res.Items))
+	for _, it := range res.Items {
+		if it.Error != "" || len(it.Stages) != 2 {
+			continue
+		}
+		sell, buy := it.Stages[0], it.Stages[1]
+		rows = append(rows, RouteSummary{
+			Name:     ScenarioName(it.Scenario),
+			Sold:     it.TotalCost.Decimal,
+			Unsold:   it.Unspent.Decimal,
+			USDT:     sell.USDT.Decimal,
+			Received: it.Generated.Decimal,
+			Leftover: sell.USDT.Sub(buy.USDT.Decimal),
+		})
+	}
+	return rows
+}
+
+// ScenarioName — название сценария для вывода CLI по его ID.
+func ScenarioName(id string) string {
+	if e, ok := scenario.Lookup(id); ok {
+		return e.Strategy.Name()
+	}
+	return id
+}
+
+// Печать одного этапа маршрута монета → USDT → монета
+func (c *CLIPresenter) RenderRouteStage(title string, st planner.Stage) {
+	fmt.Fprintf(c.out, "\n== %s ==\n", title)
+	if len(st.Legs) == 0 || st.Amount.IsZero() {
+		fmt.Fprintln(c.out, "Нет данных для отображения.")
+		return
+	}
+
+	fmt.Fprintf(c.out, "Asset: %s\n", st.Coin)
+	fmt.Fprintf(c.out, "VWAP:  %s\n", st.VWAP.StringFixed(8))
+	fmt.Fprintf(c.out, "Итого монет: %s\n", st.Amount.StringFixed(8))
+	fmt.Fprintf(c.out, "Итого USDT:  %s\n", st.USDT.StringFixed(2))
+
+	legs := append([]planner.Leg(nil), st.Legs...)
+	sort.Slice(legs, func(i, j int) bool { return legs[i].Amount.GreaterThan(legs[j].Amount.Decimal) })
+
+	fmt.Fprintln(c.out, "\nБиржа            Кол-во (qty)        Цена (avg)        Сумма (USDT)")
+	fmt.Fprintln(c.out, "---------------------------------------------------------------------")
+	for _, l := range legs {
+		fmt.Fprintf(c.out, "%-16s %-18s %-16s %s\n", l.Exchange, l.Amount.StringFixed(8), l.Price.StringFixed(8), l.Amount.Mul(l.Price.Decimal).StringFixed(2))
+	}
+}
 
-	fmt.Println("\n=== Сравнение сценариев ===")
-	fmt.Println("Сценарий                         VWAP              Qty              USDT")
-	fmt.Println("--------------------------------------------------------------------------")
+// Сравнение сценариев для обмена монета → монета: лучший тот, кто получил больше to
+func (c *CLIPresenter) RenderRouteComparisons(from, to string, rows []RouteSummary) {
+	xs := append([]RouteSummary(nil), rows...)
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Received.GreaterThan(xs[j].Received) })
+
+	fmt.Fprintf(c.out, "\n=== Сравнение сценариев %s → USDT → %s ===\n", from, to)
+	fmt.Fprintf(c.out, "%-40s %-18s %-16s %-18s %s\n", "Сценарий", "Продано "+from, "USDT", "Получено "+to, "Курс "+to+"/"+from)
+	fmt.Fprintln(c.out, "----------------------------------------------------------------------------------------------------------")
 	for _, x := range xs {
-		fmt.Printf("%-30s %-17.8f %-16.8f %.2f\n", x.name, x.vwap, x.qty, x.usdt)
+		rate := money.Rate(money.Div(x.Received, x.Sold))
+		fmt.Fprintf(c.out, "%-40s %-18s %-16s %-18s %s\n", x.Name, x.Sold.StringFixed(8), x.USDT.StringFixed(2), x.Received.StringFixed(8), rate)
+		if x.Sold.IsPositive() && x.Unsold.IsPositive() {
+			fmt.Fprintf(c.out, "  не продано из-за глубины: %s %s\n", x.Unsold.StringFixed(8), from)
+		}
+		if x.Leftover.GreaterThanOrEqual(cent) {
+			fmt.Fprintf(c.out, "  не израсходовано на втором этапе: %s USDT\n", x.Leftover.StringFixed(2))
+		}
 	}
 }
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BookRequest — параметры /api/book.
+type BookRequest struct {
+	Coin  string
+	Tick  float64
+	Bps   float64
+	Bands []float64
+	Limit int
+
+	Exchanges []string
+}
+
+// BookRow — строка сводного стакана с разбивкой объёма по биржам.
+type BookRow struct {
+	Price       float64            `json:"price"`
+	Qty         float64            `json:"qty"`
+	Notional    float64            `json:"notional"`
+	CumQty      float64            `json:"cumQty"`
+	CumNotional float64            `json:"cumNotional"`
+	Exchanges   map[string]float64 `json:"exchanges"`
+}
+
+// DepthBand — глубина в пределах ±pct% от mid.
+type DepthBand struct {
+	Pct         float64 `json:"pct"`
+	AskQty      float64 `json:"askQty"`
+	AskNotional float64 `json:"askNotional"`
+	BidQty      float64 `json:"bidQty"`
+	BidNotional float64 `json:"bidNotional"`
+}
+
+// BookResponse — ответ на /api/book: сводная лестница <coin>/USDT.
+type BookResponse struct {
+	Coin        string      `json:"coin"`
+	BestAsk     float64     `json:"bestAsk"`
+	BestBid     float64     `json:"bestBid"`
+	Mid         float64     `json:"mid"`
+	Asks        []BookRow   `json:"asks"`
+	Bids        []BookRow   `json:"bids"`
+	Depth       []DepthBand `json:"depth"`
+	Exchanges   []string    `json:"exchanges"`
+	Diagnostics []string    `json:"diagnostics"`
+	Issues      []BookIssue `json:"issues,omitempty"`
+	BookAges    []BookAge   `json:"bookAges,omitempty"`
+	GeneratedAt string      `json:"generatedAt"`
+}
+
+// handleBook обрабатывает GET /api/book?coin=ETH[&tick=1|&bps=5][&bands=0.5,1,2][&limit=200][&exchanges=binance,okx]
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	req := BookRequest{Coin: strings.ToUpper(strings.TrimSpace(q.Get("coin")))}
+	if req.Coin == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "missing 'coin' query param"})
+		return
+	}
+
+	var err error
+	if req.Tick, err = parseFloatParam(q.Get("tick")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'tick': " + err.Error()})
+		return
+	}
+	if req.Bps, err = parseFloatParam(q.Get("bps")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'bps': " + err.Error()})
+		return
+	}
+	if raw := strings.TrimSpace(q.Get("bands")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			v, err := parseFloatParam(part)
+			if err != nil || v <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'bands': " + part})
+				return
+			}
+			req.Bands = append(req.Bands, v)
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'limit': " + raw})
+			return
+		}
+		req.Limit = n
+	}
+
+	if raw := strings.TrimSpace(q.Get("exchanges")); raw != "" {
+		req.Exchanges = strings.Split(raw, ",")
+	}
+
+	ctx, cancel := s.exchangeContext(r)
+	defer cancel()
+
+	res, err := s.flow.Book(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// parseFloatParam разбирает необязательный неотрицательный параметр (пусто → 0, запятая допустима).
+func parseFloatParam(raw string) (float64, error) {
+	raw = strings.ReplaceAll(strings.TrimSpace(raw), ",", ".")
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, strconv.ErrRange
+	}
+	return v, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"io/fs"
 	"log"
 	"net/http"
@@ -17,23 +18,70 @@ var embeddedFS embed.FS
 
 type FlowFacade interface {
 	Plan(ctx context.Context, req PlanRequest) (PlanResponse, error)
+	Compare(ctx context.Context, req PlanRequest) (CompareResponse, error)
+	ExportPlan(ctx context.Context, req PlanRequest, format string) (ExportFile, error)
+	ExportCompare(ctx context.Context, req PlanRequest, format string) (ExportFile, error)
+	Book(ctx context.Context, req BookRequest) (BookResponse, error)
+	GetPlan(ctx context.Context, id string) (StoredPlanResponse, error)
+	ListPlans(ctx context.Context, q PlansQuery) (PlansListResponse, error)
+	Requote(ctx context.Context, id string) (RequoteResponse, error)
+	Drift() DriftResponse
+	Health() HealthResponse
+	Scenarios() ScenariosResponse
 }
 
 type Server struct {
-	addr   string
-	flow   FlowFacade
-	server *http.Server
+	addr       string
+	flow       FlowFacade
+	quotes     QuoteFacade   // необязательно: клиентские котировки
+	coins      []string      // монеты для /api/symbols (пусто — список по умолчанию)
+	timeout    time.Duration // таймаут запросов, которые ходят на биржи (0 — 10s)
+	server     *http.Server
+	onShutdown []func()
+	onClose    []func() error
 }
 
+// defaultCoins — монеты /api/symbols, если список не задан конфигом.
+var defaultCoins = []string{"BTC", "ETH", "BNB", "SOL", "XRP", "ADA", "DOGE", "TON", "TRX", "DOT"}
+
 func New(addr string, flow FlowFacade) *Server { return &Server{addr: addr, flow: flow} }
 
+// WithCoins задаёт монеты, которые отдаёт /api/symbols.
+func (s *Server) WithCoins(coins []string) *Server {
+	s.coins = coins
+	return s
+}
+
+// WithRequestTimeout задаёт таймаут запросов, которые тянут стаканы с бирж.
+func (s *Server) WithRequestTimeout(d time.Duration) *Server {
+	s.timeout = d
+	return s
+}
+
+// exchangeContext — контекст запроса с таймаутом на походы на биржи.
+func (s *Server) exchangeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	d := s.timeout
+	if d <= 0 {
+		d = 10 * time.Second
+	}
+	return context.WithTimeout(r.Context(), d)
+}
+
 func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 
 	// API
 	mux.HandleFunc("/api/health", s.handleHealth)
 	mux.HandleFunc("/api/plan", s.handlePlan)
+	mux.HandleFunc("/api/compare", s.handleCompare) // все сценарии на одних стаканах
+	mux.HandleFunc("/api/plans", s.handlePlans)     // история планов
+	mux.HandleFunc("/api/plans/", s.handlePlanByID) // план по ID (ссылка на котировку)
+	mux.HandleFunc("/api/drift", s.handleDrift)     // котировки с дрейфом выше порога
+	mux.HandleFunc("/api/quotes", s.handleQuote)
+	mux.HandleFunc("/api/quotes/verify", s.handleQuoteVerify)
+	mux.HandleFunc("/api/book", s.handleBook)       // сводный стакан по всем биржам
 	mux.HandleFunc("/api/symbols", s.handleSymbols) // только USDT как quote
+	mux.HandleFunc("/api/scenarios", s.handleScenarios)
 
 	// static
 	sub, err := fs.Sub(embeddedFS, "webui")
@@ -57,21 +105,101 @@ func (s *Server) Start() error {
 	return s.server.ListenAndServe()
 }
 
+// OnShutdown регистрирует остановку фоновых задач при Shutdown.
+func (s *Server) OnShutdown(f func()) { s.onShutdown = append(s.onShutdown, f) }
+
+// OnClose регистрирует закрытие ресурсов (файлов и т.п.): оно выполняется после
+// остановки HTTP-сервера, когда запросы в них уже не пишут.
+func (s *Server) OnClose(f func() error) { s.onClose = append(s.onClose, f) }
+
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.server == nil {
-		return nil
+	for _, f := range s.onShutdown {
+		f()
 	}
-	return s.server.Shutdown(ctx)
+	var err error
+	if s.server != nil {
+		err = s.server.Shutdown(ctx)
+	}
+	for _, f := range s.onClose {
+		err = errors.Join(err, f())
+	}
+	return err
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	_ = json.NewEncoder(w).Encode(s.flow.Health())
 }
 func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePlanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := s.exchangeContext(r)
+	defer cancel()
+
+	// ?export=csv|xlsx|pdf — вместо JSON отдаём файл
+	if f := r.URL.Query().Get("export"); f != "" {
+		file, err := s.flow.ExportPlan(ctx, req, f)
+		writeExport(w, file, err)
+		return
+	}
+
+	res, err := s.flow.Plan(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// handleCompare — все сценарии на одном наборе стаканов (поле scenario игнорируется).
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodePlanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := s.exchangeContext(r)
+	defer cancel()
+
+	if f := r.URL.Query().Get("export"); f != "" {
+		file, err := s.flow.ExportCompare(ctx, req, f)
+		writeExport(w, file, err)
+		return
+	}
+
+	res, err := s.flow.Compare(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// writeExport отдаёт выгрузку как вложение (ошибка — обычным JSON).
+func writeExport(w http.ResponseWriter, f ExportFile, err error) {
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", f.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+f.Name+`"`)
+	_, _ = w.Write(f.Data)
+}
+
+// decodePlanRequest разбирает и валидирует тело POST-запроса с PlanRequest.
+// При ошибке сам пишет ответ и возвращает ok=false.
+func decodePlanRequest(w http.ResponseWriter, r *http.Request) (PlanRequest, bool) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+		return PlanRequest{}, false
 	}
 	w.Header().Set("Content-Type", "application/json")
 
@@ -79,7 +207,7 @@ func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid JSON: " + err.Error()})
-		return
+		return PlanRequest{}, false
 	}
 
 	// Нормализация
@@ -90,46 +218,40 @@ func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
 	if req.Base == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "base is required"})
-		return
+		return PlanRequest{}, false
 	}
 	if req.Quote == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "quote is required"})
-		return
+		return PlanRequest{}, false
 	}
 	if strings.EqualFold(req.Base, req.Quote) {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "Нельзя выбирать одинаковые монеты (выберите разные в полях «Отдаёте» и «Получаете»)."})
-		return
+		return PlanRequest{}, false
 	}
 	if req.Amount <= 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "amount must be > 0"})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
-
-	res, err := s.flow.Plan(ctx, req)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
-		return
+		return PlanRequest{}, false
 	}
+	return req, true
+}
 
-	_ = json.NewEncoder(w).Encode(res)
+// handleScenarios — список сценариев с названиями и описаниями для интерфейса.
+func (s *Server) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.flow.Scenarios())
 }
 
 func (s *Server) handleSymbols(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	all := []string{
-		"USDT",
-		"BTC", "ETH", "BNB", "SOL", "XRP", "ADA", "DOGE", "TON", "TRX", "DOT",
+	coins := s.coins
+	if len(coins) == 0 {
+		coins = defaultCoins
 	}
-
-	all = uniqStrings(all)
+	all := uniqStrings(append([]string{"USDT"}, coins...))
 	sort.Strings(all)
 
 	resp := map[string]any{
@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cryptobot/internal/usecase/planner"
+)
+
+// noBooks — репозиторий, до которого невалидный запрос дойти не должен.
+type noBooks struct{ t *testing.T }
+
+func (r noBooks) FetchAllBooks(context.Context, string, int) ([]planner.Book, []string, error) {
+	r.t.Error("books fetched for an invalid request")
+	return nil, nil, nil
+}
+
+func TestPlanUnknownScenario(t *testing.T) {
+	srv := New("", &PlannerAdapter{Svc: planner.New(noBooks{t})})
+	for _, path := range []string{"/api/plan", "/api/plan?export=csv"} {
+		t.Run(path, func(t *testing.T) {
+			body := `{"base":"BTC","quote":"USDT","amount":100,"scenario":"cheapest"}`
+			rec := httptest.NewRecorder()
+			srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			var e ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&e); err != nil || !strings.Contains(e.Error, "best_single") {
+				t.Errorf("error = %q (%v), want the supported scenarios", e.Error, err)
+			}
+		})
+	}
+}
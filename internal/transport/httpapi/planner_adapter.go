@@ -1,38 +1,316 @@
 package httpapi
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
+	"cryptobot/internal/infra/bookcache"
+	"cryptobot/internal/shared/breaker"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/export"
+	"cryptobot/internal/usecase/orderbook"
 	"cryptobot/internal/usecase/planner"
+	"cryptobot/internal/usecase/scenario"
 )
 
 // PlannerAdapter — тонкий адаптер: маппит httpapi.Plan* <-> planner.* и вызывает use-case.
 type PlannerAdapter struct {
-	Svc *planner.Service
+	Svc     *planner.Service
+	Monitor *planner.DriftMonitor // необязательно: фоновая проверка дрейфа котировок
+	Venues  BreakerSource         // необязательно: автоматы бирж для /api/health
+
+	// Необязательно: кэш стаканов и фоновый прогрев для /api/health
+	Cache    CacheSource
+	Prefetch PrefetchSource
+}
+
+// BreakerSource — источник состояния автоматов бирж (exchangebooks.HTTPRepo).
+type BreakerSource interface {
+	Breakers() []breaker.Status
+}
+
+// CacheSource — счётчики кэша стаканов (bookcache.Repo).
+type CacheSource interface {
+	Stats() bookcache.Stats
+}
+
+// PrefetchSource — состояние фонового прогрева (bookcache.Prefetcher).
+type PrefetchSource interface {
+	Status() bookcache.PrefetchStatus
 }
 
 // Гарантируем совместимость с ожидаемым интерфейсом httpapi.Server (Plan(ctx, PlanRequest) ...).
 func (a *PlannerAdapter) Plan(ctx context.Context, req PlanRequest) (PlanResponse, error) {
-	in := planner.Request{
+	out, err := a.Svc.Plan(ctx, toPlannerRequest(req))
+	if err != nil {
+		return PlanResponse{}, err
+	}
+	return toPlanResponse(out), nil
+}
+
+// Compare — все сценарии на одном наборе стаканов.
+func (a *PlannerAdapter) Compare(ctx context.Context, req PlanRequest) (CompareResponse, error) {
+	out, err := a.Svc.Compare(ctx, toPlannerRequest(req))
+	if err != nil {
+		return CompareResponse{}, err
+	}
+	items := make([]CompareItem, 0, len(out.Items))
+	for _, it := range out.Items {
+		items = append(items, CompareItem{
+			PlanResponse: toPlanResponse(it.Result),
+			Rank:         it.Rank,
+			SavingsAbs:   it.SavingsAbs,
+			SavingsBps:   it.SavingsBps,
+			SavingsUnit:  it.SavingsUnit,
+			Error:        it.Error,
+		})
+	}
+	return CompareResponse{
+		Base:        out.Base,
+		Quote:       out.Quote,
+		Amount:      out.Amount,
+		Results:     items,
+		Diagnostics: out.Diagnostics,
+		Issues:      toBookIssues(out.Issues),
+		BookAges:    toBookAges(out.BookAges),
+		GeneratedAt: out.GeneratedAt,
+	}, nil
+}
+
+// ExportPlan — план одного сценария файлом.
+func (a *PlannerAdapter) ExportPlan(ctx context.Context, req PlanRequest, format string) (ExportFile, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return ExportFile{}, err
+	}
+	out, err := a.Svc.Plan(ctx, toPlannerRequest(req))
+	if err != nil {
+		return ExportFile{}, err
+	}
+	var buf bytes.Buffer
+	if err := export.Plan(&buf, f, out); err != nil {
+		return ExportFile{}, err
+	}
+	name := fmt.Sprintf("plan_%s_%s_%s.%s", out.Base, out.Quote, out.Scenario, f)
+	return ExportFile{Name: name, ContentType: f.ContentType(), Data: buf.Bytes()}, nil
+}
+
+// ExportCompare — сравнение всех сценариев файлом.
+func (a *PlannerAdapter) ExportCompare(ctx context.Context, req PlanRequest, format string) (ExportFile, error) {
+	f, err := export.ParseFormat(format)
+	if err != nil {
+		return ExportFile{}, err
+	}
+	out, err := a.Svc.Compare(ctx, toPlannerRequest(req))
+	if err != nil {
+		return ExportFile{}, err
+	}
+	var buf bytes.Buffer
+	if err := export.Compare(&buf, f, out); err != nil {
+		return ExportFile{}, err
+	}
+	name := fmt.Sprintf("compare_%s_%s.%s", out.Base, out.Quote, f)
+	return ExportFile{Name: name, ContentType: f.ContentType(), Data: buf.Bytes()}, nil
+}
+
+func toPlannerRequest(req PlanRequest) planner.Request {
+	return planner.Request{
 		Base:     strings.ToUpper(strings.TrimSpace(req.Base)),
 		Quote:    strings.ToUpper(strings.TrimSpace(req.Quote)),
 		Amount:   req.Amount,
 		Scenario: req.Scenario,
+
+		Exchanges: req.Exchanges,
+
+		SlippageBps: req.SlippageBps,
+	}
+}
+
+// GetPlan — сохранённый план по ID.
+func (a *PlannerAdapter) GetPlan(ctx context.Context, id string) (StoredPlanResponse, error) {
+	p, err := a.Svc.GetPlan(ctx, id)
+	if errors.Is(err, planner.ErrPlanNotFound) {
+		return StoredPlanResponse{}, ErrNotFound
 	}
-	out, err := a.Svc.Plan(ctx, in)
 	if err != nil {
-		return PlanResponse{}, err
+		return StoredPlanResponse{}, err
+	}
+	return toStoredPlanResponse(p), nil
+}
+
+// ListPlans — страница истории планов.
+func (a *PlannerAdapter) ListPlans(ctx context.Context, q PlansQuery) (PlansListResponse, error) {
+	plans, total, err := a.Svc.ListPlans(ctx, planner.PlanFilter{
+		Base:     q.Base,
+		Quote:    q.Quote,
+		Scenario: q.Scenario,
+		From:     q.From,
+		To:       q.To,
+		Offset:   q.Offset,
+		Limit:    q.Limit,
+	})
+	if err != nil {
+		return PlansListResponse{}, err
+	}
+	items := make([]StoredPlanResponse, 0, len(plans))
+	for _, p := range plans {
+		items = append(items, toStoredPlanResponse(p))
+	}
+	return PlansListResponse{Items: items, Total: total, Offset: q.Offset, Limit: q.Limit}, nil
+}
+
+// Requote — пересчёт сохранённого плана по текущим стаканам.
+func (a *PlannerAdapter) Requote(ctx context.Context, id string) (RequoteResponse, error) {
+	rq, err := a.Svc.Requote(ctx, id)
+	if errors.Is(err, planner.ErrPlanNotFound) {
+		return RequoteResponse{}, ErrNotFound
+	}
+	if err != nil {
+		return RequoteResponse{}, err
+	}
+	return toRequoteResponse(rq), nil
+}
+
+// Drift — планы, помеченные фоновой проверкой дрейфа.
+func (a *PlannerAdapter) Drift() DriftResponse {
+	if a.Monitor == nil {
+		return DriftResponse{Items: []RequoteResponse{}}
+	}
+	flagged, at := a.Monitor.Flagged()
+	out := DriftResponse{Enabled: true, Items: make([]RequoteResponse, 0, len(flagged))}
+	if !at.IsZero() {
+		out.CheckedAt = at.Format(time.RFC3339)
+	}
+	for _, rq := range flagged {
+		out.Items = append(out.Items, toRequoteResponse(rq))
 	}
+	return out
+}
+
+// Scenarios — сценарии из реестра.
+func (a *PlannerAdapter) Scenarios() ScenariosResponse {
+	all := scenario.All()
+	out := ScenariosResponse{Default: a.Svc.DefaultScenario(), Scenarios: make([]ScenarioInfo, 0, len(all))}
+	for _, e := range all {
+		out.Scenarios = append(out.Scenarios, ScenarioInfo{
+			ID:          e.ID,
+			Title:       map[string]string{"ru": e.Title.RU, "en": e.Title.EN},
+			Description: map[string]string{"ru": e.Description.RU, "en": e.Description.EN},
+		})
+	}
+	return out
+}
+
+func (a *PlannerAdapter) Health() HealthResponse {
+	out := HealthResponse{Status: "ok", Cache: a.cacheHealth()}
+	if a.Venues == nil {
+		return out
+	}
+	open := 0
+	for _, st := range a.Venues.Breakers() {
+		h := ExchangeHealth{Name: st.Name, State: string(st.State), Failures: st.Failures, LastError: st.LastError}
+		if !st.OpenUntil.IsZero() {
+			h.OpenUntil = st.OpenUntil.Format(time.RFC3339)
+		}
+		if st.State != breaker.Closed {
+			open++
+		}
+		out.Exchanges = append(out.Exchanges, h)
+	}
+	switch {
+	case open > 0 && open == len(out.Exchanges):
+		out.Status = "down"
+	case open > 0:
+		out.Status = "degraded"
+	}
+	return out
+}
+
+func (a *PlannerAdapter) cacheHealth() *CacheHealth {
+	if a.Cache == nil {
+		return nil
+	}
+	st := a.Cache.Stats()
+	out := &CacheHealth{
+		Hits:       st.Hits,
+		Shared:     st.Shared,
+		Misses:     st.Misses,
+		Prefetches: st.Prefetches,
+		HitRatio:   st.HitRatio,
+	}
+	if a.Prefetch != nil {
+		ps := a.Prefetch.Status()
+		out.Hot = ps.Hot
+		out.PrefetchInterval = ps.Interval.String()
+		out.RateLimited = ps.RateLimited
+		if !ps.LastRun.IsZero() {
+			out.LastPrefetch = ps.LastRun.Format(time.RFC3339)
+		}
+	}
+	return out
+}
+
+func toRequoteResponse(rq planner.RequoteResult) RequoteResponse {
+	legs := make([]LegChangeResponse, 0, len(rq.Legs))
+	for _, l := range rq.Legs {
+		legs = append(legs, LegChangeResponse{
+			Exchange:  l.Exchange,
+			OldAmount: money.Num(l.OldAmount),
+			NewAmount: money.Num(l.NewAmount),
+			OldPrice:  money.Num(l.OldPrice),
+			NewPrice:  money.Num(l.NewPrice),
+		})
+	}
+	return RequoteResponse{
+		ID:        rq.ID,
+		CreatedAt: rq.CreatedAt.Format(time.RFC3339),
+		CheckedAt: rq.CheckedAt.Format(time.RFC3339),
+		Original:  toPlanResponse(rq.Original),
+		Current:   toPlanResponse(rq.Current),
+		VWAPDiff:  money.Num(rq.VWAPDiff),
+		DriftPct:  rq.DriftPct,
+		Legs:      legs,
+	}
+}
+
+func toStoredPlanResponse(p planner.StoredPlan) StoredPlanResponse {
+	return StoredPlanResponse{
+		ID:        p.ID,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		Request: PlanRequest{
+			Base:     p.Request.Base,
+			Quote:    p.Request.Quote,
+			Amount:   p.Request.Amount,
+			Scenario: p.Request.Scenario,
+
+			Exchanges: p.Request.Exchanges,
+
+			SlippageBps: p.Request.SlippageBps,
+		},
+		Plan:    toPlanResponse(p.Result),
+		BookRef: p.BookRef,
+	}
+}
+
+func toPlanResponse(out planner.Result) PlanResponse {
 	legs := make([]PlanLeg, 0, len(out.Legs))
 	for _, l := range out.Legs {
 		legs = append(legs, PlanLeg{
 			Exchange: l.Exchange,
 			Amount:   l.Amount,
 			Price:    l.Price,
+
+			DepthUsedPct: l.DepthUsedPct,
+			LastLevel:    l.LastLevel,
+			Exhausted:    l.Exhausted,
 		})
 	}
 	return PlanResponse{
+		ID:          out.ID,
 		Scenario:    out.Scenario,
 		Base:        out.Base,
 		Quote:       out.Quote,
@@ -42,6 +320,119 @@ func (a *PlannerAdapter) Plan(ctx context.Context, req PlanRequest) (PlanRespons
 		Generated:   out.Generated,
 		Legs:        legs,
 		Diagnostics: out.Diagnostics,
+		Issues:      toBookIssues(out.Issues),
+		BookAges:    toBookAges(out.BookAges),
+		Confidence:  out.Confidence,
+		Venues:      toVenueSelections(out.Venues),
+		GeneratedAt: out.GeneratedAt,
+
+		RiskAdjustedVWAP: out.RiskAdjustedVWAP,
+	}
+}
+
+func toVenueSelections(in []planner.VenueSelection) []VenueSelection {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]VenueSelection, 0, len(in))
+	for _, v := range in {
+		out = append(out, VenueSelection{
+			Coin:         v.Coin,
+			Exchanges:    v.Exchanges,
+			ToleranceBps: v.ToleranceBps,
+			OptimalVWAP:  v.OptimalVWAP,
+			CostDiffUSDT: v.CostDiffUSDT,
+			CostDiffBps:  v.CostDiffBps,
+		})
+	}
+	return out
+}
+
+// Book — сводный стакан <coin>/USDT для графика глубины.
+func (a *PlannerAdapter) Book(ctx context.Context, req BookRequest) (BookResponse, error) {
+	out, err := a.Svc.Book(ctx, planner.BookRequest{
+		Coin:  req.Coin,
+		Tick:  req.Tick,
+		Bps:   req.Bps,
+		Bands: req.Bands,
+		Limit: req.Limit,
+
+		Exchanges: req.Exchanges,
+	})
+	if err != nil {
+		return BookResponse{}, err
+	}
+	depth := make([]DepthBand, 0, len(out.Depth))
+	for _, d := range out.Depth {
+		depth = append(depth, DepthBand{
+			Pct:         d.Pct,
+			AskQty:      d.AskQty,
+			AskNotional: d.AskNotional,
+			BidQty:      d.BidQty,
+			BidNotional: d.BidNotional,
+		})
+	}
+	return BookResponse{
+		Coin:        out.Coin,
+		BestAsk:     out.BestAsk,
+		BestBid:     out.BestBid,
+		Mid:         out.Mid,
+		Asks:        toBookRows(out.Asks),
+		Bids:        toBookRows(out.Bids),
+		Depth:       depth,
+		Exchanges:   out.Exchanges,
+		Diagnostics: out.Diagnostics,
+		Issues:      toBookIssues(out.Issues),
+		BookAges:    toBookAges(out.BookAges),
 		GeneratedAt: out.GeneratedAt,
 	}, nil
 }
+
+func toBookIssues(src []planner.BookIssue) []BookIssue {
+	if len(src) == 0 {
+		return nil
+	}
+	out := make([]BookIssue, 0, len(src))
+	for _, is := range src {
+		out = append(out, BookIssue{
+			Coin:     is.Coin,
+			Exchange: is.Exchange,
+			Check:    is.Check,
+			Excluded: is.Excluded,
+			Detail:   is.Detail,
+		})
+	}
+	return out
+}
+
+func toBookAges(src []planner.BookAge) []BookAge {
+	if len(src) == 0 {
+		return nil
+	}
+	out := make([]BookAge, 0, len(src))
+	for _, a := range src {
+		out = append(out, BookAge{
+			Coin:     a.Coin,
+			Exchange: a.Exchange,
+			AgeMs:    a.AgeMs,
+			Source:   a.Source,
+			Stale:    a.Stale,
+		})
+	}
+	return out
+}
+
+func toBookRows(src []orderbook.LadderRow) []BookRow {
+	rows := make([]BookRow, 0, len(src))
+	for _, r := range src {
+		rows = append(rows, BookRow{
+			Price:       r.Price,
+			Qty:         r.Qty,
+			Notional:    r.Notional,
+			CumQty:      r.CumQty,
+			CumNotional: r.CumNotional,
+			Exchanges:   r.ByExchange,
+		})
+	}
+	return rows
+}
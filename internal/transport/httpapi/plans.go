@@ -0,0 +1,219 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cryptobot/internal/shared/money"
+)
+
+// ErrNotFound — запрошенный объект не найден (маппится в 404).
+var ErrNotFound = errors.New("not found")
+
+// PlansQuery — фильтры и пагинация для /api/plans.
+type PlansQuery struct {
+	Base     string
+	Quote    string
+	Scenario string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
+
+// StoredPlanResponse — сохранённый план с исходным запросом.
+type StoredPlanResponse struct {
+	ID        string       `json:"id"`
+	CreatedAt string       `json:"createdAt"` // RFC3339
+	Request   PlanRequest  `json:"request"`
+	Plan      PlanResponse `json:"plan"`
+	BookRef   string       `json:"bookRef"`
+}
+
+// PlansListResponse — страница истории планов.
+type PlansListResponse struct {
+	Items  []StoredPlanResponse `json:"items"`
+	Total  int                  `json:"total"`
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
+
+// LegChangeResponse — изменение ножки между исходным планом и перерасчётом.
+type LegChangeResponse struct {
+	Exchange  string       `json:"exchange"`
+	OldAmount money.Number `json:"oldAmount"`
+	NewAmount money.Number `json:"newAmount"`
+	OldPrice  money.Number `json:"oldPrice"`
+	NewPrice  money.Number `json:"newPrice"`
+}
+
+// RequoteResponse — ответ на /api/plans/{id}/requote.
+type RequoteResponse struct {
+	ID        string              `json:"id"`
+	CreatedAt string              `json:"createdAt"`
+	CheckedAt string              `json:"checkedAt"`
+	Original  PlanResponse        `json:"original"`
+	Current   PlanResponse        `json:"current"`
+	VWAPDiff  money.Number        `json:"vwapDiff"`
+	DriftPct  float64             `json:"driftPct"` // >0 — цена ухудшилась для клиента
+	Legs      []LegChangeResponse `json:"legs"`
+}
+
+// DriftResponse — ответ на /api/drift: планы, чей дрейф превысил порог.
+type DriftResponse struct {
+	Enabled   bool              `json:"enabled"`
+	CheckedAt string            `json:"checkedAt,omitempty"`
+	Items     []RequoteResponse `json:"items"`
+}
+
+const (
+	defaultPlansLimit = 20
+	maxPlansLimit     = 200
+)
+
+// handlePlans обрабатывает GET /api/plans?base=&quote=&scenario=&from=&to=&offset=&limit=
+// from/to — дата (2006-01-02) или RFC3339; to по дате включает весь день.
+func (s *Server) handlePlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	f := PlansQuery{
+		Base:     strings.ToUpper(strings.TrimSpace(q.Get("base"))),
+		Quote:    strings.ToUpper(strings.TrimSpace(q.Get("quote"))),
+		Scenario: strings.ToLower(strings.TrimSpace(q.Get("scenario"))),
+		Limit:    defaultPlansLimit,
+	}
+
+	var err error
+	if f.From, err = parseDateParam(q.Get("from"), false); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'from': " + err.Error()})
+		return
+	}
+	if f.To, err = parseDateParam(q.Get("to"), true); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'to': " + err.Error()})
+		return
+	}
+	if raw := strings.TrimSpace(q.Get("offset")); raw != "" {
+		if f.Offset, err = strconv.Atoi(raw); err != nil || f.Offset < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'offset': " + raw})
+			return
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		if f.Limit, err = strconv.Atoi(raw); err != nil || f.Limit <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid 'limit': " + raw})
+			return
+		}
+	}
+	if f.Limit > maxPlansLimit {
+		f.Limit = maxPlansLimit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := s.flow.ListPlans(ctx, f)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// handlePlanByID обрабатывает GET /api/plans/{id}
+func (s *Server) handlePlanByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/plans/"), "/")
+	if id == "" {
+		s.handlePlans(w, r)
+		return
+	}
+	if rest, ok := strings.CutSuffix(id, "/requote"); ok {
+		s.handleRequote(w, r, rest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := s.flow.GetPlan(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "plan " + id + " not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// handleRequote обрабатывает GET /api/plans/{id}/requote — пересчёт по текущим стаканам.
+func (s *Server) handleRequote(w http.ResponseWriter, r *http.Request, id string) {
+	ctx, cancel := s.exchangeContext(r)
+	defer cancel()
+
+	res, err := s.flow.Requote(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "plan " + id + " not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// handleDrift обрабатывает GET /api/drift — результаты фоновой проверки дрейфа.
+func (s *Server) handleDrift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.flow.Drift())
+}
+
+// parseDateParam принимает 2006-01-02 или RFC3339. Для endOfDay дата без времени
+// превращается в начало следующего дня (граница «до» не включается).
+func parseDateParam(raw string, endOfDay bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
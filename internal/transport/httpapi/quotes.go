@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"cryptobot/internal/shared/money"
+)
+
+// QuoteFacade — клиентские котировки (наценка, срок действия, подпись).
+type QuoteFacade interface {
+	Quote(ctx context.Context, req QuoteRequest) (QuoteResponse, error)
+	Verify(q QuoteResponse) VerifyResponse
+}
+
+type QuoteRequest struct {
+	PlanRequest
+	Tier string `json:"tier"`
+}
+
+// QuoteResponse — подписанная котировка. Именно этот объект клиент присылает на /api/quotes/verify.
+// Рыночной цены и маржи деска в нём нет: наценку контрагенту не раскрываем.
+type QuoteResponse struct {
+	PlanID      string       `json:"planId,omitempty"`
+	Tier        string       `json:"tier"`
+	Base        string       `json:"base"`
+	Quote       string       `json:"quote"`
+	Amount      float64      `json:"amount"`
+	Scenario    string       `json:"scenario"`
+	ClientPrice money.Number `json:"clientPrice"`
+	Receive     money.Number `json:"receive"`
+	ReceiveUnit string       `json:"receiveUnit"`
+	IssuedAt    string       `json:"issuedAt"`  // RFC3339
+	ExpiresAt   string       `json:"expiresAt"` // RFC3339
+	Signature   string       `json:"signature"`
+}
+
+type VerifyResponse struct {
+	Valid     bool   `json:"valid"`
+	Signed    bool   `json:"signed"`
+	Expired   bool   `json:"expired"`
+	ExpiresIn int64  `json:"expiresInSec"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// WithQuotes подключает котирование (/api/quotes, /api/quotes/verify).
+func (s *Server) WithQuotes(q QuoteFacade) *Server {
+	s.quotes = q
+	return s
+}
+
+// handleQuote обрабатывает POST /api/quotes {base, quote, amount, scenario, tier}
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	if s.quotes == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	var req QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	req.Base = strings.ToUpper(strings.TrimSpace(req.Base))
+	req.Quote = strings.ToUpper(strings.TrimSpace(req.Quote))
+	if req.Base == "" || req.Quote == "" || req.Amount <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "base, quote and amount > 0 are required"})
+		return
+	}
+
+	ctx, cancel := s.exchangeContext(r)
+	defer cancel()
+
+	res, err := s.quotes.Quote(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// handleQuoteVerify обрабатывает POST /api/quotes/verify с телом ранее выданной котировки.
+func (s *Server) handleQuoteVerify(w http.ResponseWriter, r *http.Request) {
+	if s.quotes == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	var q QuoteResponse
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.quotes.Verify(q))
+}
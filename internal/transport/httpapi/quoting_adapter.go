@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/quoting"
+)
+
+// QuotingAdapter — маппит httpapi.Quote* <-> quoting.*.
+type QuotingAdapter struct {
+	Svc *quoting.Service
+}
+
+func (a *QuotingAdapter) Quote(ctx context.Context, req QuoteRequest) (QuoteResponse, error) {
+	q, err := a.Svc.Quote(ctx, quoting.Request{
+		Request: toPlannerRequest(req.PlanRequest), // биржи и допуск min_venues — как в плане
+		Tier:    req.Tier,
+	})
+	if err != nil {
+		return QuoteResponse{}, err
+	}
+	// наценка остаётся у деска: клиенту уходит только его цена
+	log.Printf("quote issued: plan=%s tier=%s %s/%s market=%s client=%s margin=%s %s",
+		q.PlanID, q.Tier, q.Base, q.Quote, q.MarketPrice, q.ClientPrice, q.DeskMargin, q.ReceiveUnit)
+	return QuoteResponse{
+		PlanID:      q.PlanID,
+		Tier:        q.Tier,
+		Base:        q.Base,
+		Quote:       q.Quote,
+		Amount:      q.Amount,
+		Scenario:    q.Scenario,
+		ClientPrice: money.Num(q.ClientPrice),
+		Receive:     money.Num(q.Receive),
+		ReceiveUnit: q.ReceiveUnit,
+		IssuedAt:    q.IssuedAt.Format(time.RFC3339),
+		ExpiresAt:   q.ExpiresAt.Format(time.RFC3339),
+		Signature:   q.Signature,
+	}, nil
+}
+
+func (a *QuotingAdapter) Verify(r QuoteResponse) VerifyResponse {
+	issued, err1 := time.Parse(time.RFC3339, r.IssuedAt)
+	expires, err2 := time.Parse(time.RFC3339, r.ExpiresAt)
+	if err1 != nil || err2 != nil {
+		return VerifyResponse{Reason: "invalid issuedAt/expiresAt"}
+	}
+	v := a.Svc.Verify(quoting.Quote{
+		PlanID:      r.PlanID,
+		Tier:        r.Tier,
+		Base:        r.Base,
+		Quote:       r.Quote,
+		Amount:      r.Amount,
+		Scenario:    r.Scenario,
+		ClientPrice: r.ClientPrice.Decimal,
+		Receive:     r.Receive.Decimal,
+		ReceiveUnit: r.ReceiveUnit,
+		IssuedAt:    issued,
+		ExpiresAt:   expires,
+		Signature:   r.Signature,
+	})
+	return VerifyResponse{
+		Valid:     v.Valid,
+		Signed:    v.Signed,
+		Expired:   v.Expired,
+		ExpiresIn: int64(v.ExpiresIn / time.Second),
+		Reason:    v.Reason,
+	}
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
+	"cryptobot/internal/usecase/quoting"
+)
+
+// recordingPlanner запоминает запрос, с которым котировка пошла в планировщик.
+type recordingPlanner struct{ got planner.Request }
+
+func (p *recordingPlanner) Plan(_ context.Context, in planner.Request) (planner.Result, error) {
+	p.got = in
+	one := money.Num(decimal.NewFromInt(1))
+	return planner.Result{Base: in.Base, Quote: in.Quote, Scenario: in.Scenario, VWAP: one, TotalCost: one, Generated: one}, nil
+}
+
+func TestQuoteKeepsPlanRequest(t *testing.T) {
+	p := &recordingPlanner{}
+	svc, err := quoting.New(p, quoting.Config{Secret: []byte("secret"), Validity: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &QuotingAdapter{Svc: svc}
+	_, err = a.Quote(context.Background(), QuoteRequest{PlanRequest: PlanRequest{
+		Base: " btc", Quote: "usdt", Amount: 1000, Scenario: "min_venues",
+		Exchanges:   []string{"okx", "bybit"},
+		SlippageBps: 15,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := planner.Request{Base: "BTC", Quote: "USDT", Amount: 1000, Scenario: "min_venues", Exchanges: []string{"okx", "bybit"}, SlippageBps: 15}
+	if p.got.Base != want.Base || p.got.Quote != want.Quote || p.got.Amount != want.Amount || p.got.Scenario != want.Scenario ||
+		!slices.Equal(p.got.Exchanges, want.Exchanges) || p.got.SlippageBps != want.SlippageBps {
+		t.Errorf("planner got %+v, want %+v", p.got, want)
+	}
+}
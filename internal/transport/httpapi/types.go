@@ -1,29 +1,102 @@
 package httpapi
 
+import "cryptobot/internal/shared/money"
+
 type PlanRequest struct {
 	Base     string  `json:"base"`
 	Quote    string  `json:"quote"`
 	Amount   float64 `json:"amount"`
 	Scenario string  `json:"scenario"`
+
+	Exchanges []string `json:"exchanges,omitempty"` // ограничить набор бирж
+
+	SlippageBps float64 `json:"slippageBps,omitempty"` // min_venues: допуск к VWAP optimal, б.п.
 }
 
 type PlanLeg struct {
-	Exchange string  `json:"exchange"`
-	Amount   float64 `json:"amount"`
-	Price    float64 `json:"price"`
+	Exchange string       `json:"exchange"`
+	Amount   money.Number `json:"amount"`
+	Price    money.Number `json:"price"`
+
+	DepthUsedPct float64 `json:"depthUsedPct"` // доля видимой стороны стакана, %
+	LastLevel    bool    `json:"lastLevel,omitempty"`
+	Exhausted    bool    `json:"exhausted,omitempty"` // видимая глубина выбрана целиком
 }
 
 type PlanResponse struct {
-	Scenario    string    `json:"scenario"`
-	Base        string    `json:"base"`
-	Quote       string    `json:"quote"`
-	VWAP        float64   `json:"vwap"`
-	TotalCost   float64   `json:"totalCost"`
-	Unspent     float64   `json:"unspent"`
-	Legs        []PlanLeg `json:"legs"`
-	GeneratedAt string    `json:"generatedAt"`
-	Generated   float64   `json:"generated"` // <-- добавили тэг
-	Diagnostics []string  `json:"diagnostics"`
+	ID          string           `json:"id,omitempty"` // ID в истории: /api/plans/{id}
+	Scenario    string           `json:"scenario"`
+	Base        string           `json:"base"`
+	Quote       string           `json:"quote"`
+	VWAP        money.Number     `json:"vwap"`
+	TotalCost   money.Number     `json:"totalCost"`
+	Unspent     money.Number     `json:"unspent"`
+	Legs        []PlanLeg        `json:"legs"`
+	GeneratedAt string           `json:"generatedAt"`
+	Generated   money.Number     `json:"generated"` // <-- добавили тэг
+	Diagnostics []string         `json:"diagnostics"`
+	Issues      []BookIssue      `json:"issues,omitempty"` // замечания проверки стаканов
+	BookAges    []BookAge        `json:"bookAges,omitempty"`
+	Confidence  string           `json:"confidence"`       // high | medium | low
+	Venues      []VenueSelection `json:"venues,omitempty"` // min_venues: выбранные биржи по этапам плана
+
+	RiskAdjustedVWAP money.Number `json:"riskAdjustedVwap,omitzero"` // VWAP с поправкой на риск бирж
+}
+
+// VenueSelection — набор бирж сценария min_venues и его цена против optimal.
+type VenueSelection struct {
+	Coin         string       `json:"coin"`
+	Exchanges    []string     `json:"exchanges"`
+	ToleranceBps float64      `json:"toleranceBps"`
+	OptimalVWAP  money.Number `json:"optimalVwap"`
+	CostDiffUSDT money.Number `json:"costDiffUsdt"`
+	CostDiffBps  float64      `json:"costDiffBps"`
+}
+
+// BookAge — возраст стакана биржи на момент расчёта.
+type BookAge struct {
+	Coin     string `json:"coin"`
+	Exchange string `json:"exchange"`
+	AgeMs    int64  `json:"ageMs"`
+	Source   string `json:"source"` // exchange — по времени биржи, received — по времени получения
+	Stale    bool   `json:"stale"`  // устарел и в расчёт не пошёл
+}
+
+// BookIssue — стакан биржи исключён из расчёта (excluded) или помечен.
+type BookIssue struct {
+	Coin     string `json:"coin"`
+	Exchange string `json:"exchange"`
+	Check    string `json:"check"` // crossed | locked | outlier | non-monotonic | empty-asks | thin-bids ...
+	Excluded bool   `json:"excluded"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// CompareItem — план одного сценария и его выгода против best_single.
+type CompareItem struct {
+	PlanResponse
+	Rank        int          `json:"rank"` // 0 — сценарий не построил план
+	SavingsAbs  money.Number `json:"savingsAbs"`
+	SavingsBps  float64      `json:"savingsBps"`
+	SavingsUnit string       `json:"savingsUnit"`
+	Error       string       `json:"error,omitempty"`
+}
+
+type CompareResponse struct {
+	Base        string        `json:"base"`
+	Quote       string        `json:"quote"`
+	Amount      float64       `json:"amount"`
+	Results     []CompareItem `json:"results"` // отсортированы от лучшего к худшему
+	Diagnostics []string      `json:"diagnostics"`
+	Issues      []BookIssue   `json:"issues,omitempty"`
+	BookAges    []BookAge     `json:"bookAges,omitempty"`
+	GeneratedAt string        `json:"generatedAt"`
+}
+
+// ExportFile — выгрузка плана (CSV/XLSX/PDF).
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
 }
 
 type SymbolsResponse struct {
@@ -31,6 +104,47 @@ type SymbolsResponse struct {
 	Quotes []string `json:"quotes"`
 }
 
+// ScenariosResponse — сценарии в порядке показа; первый — база для сравнения.
+type ScenariosResponse struct {
+	Default   string         `json:"default"` // сценарий, если в запросе не указан
+	Scenarios []ScenarioInfo `json:"scenarios"`
+}
+
+type ScenarioInfo struct {
+	ID          string            `json:"id"`
+	Title       map[string]string `json:"title"`       // по языкам: ru, en
+	Description map[string]string `json:"description"` // по языкам: ru, en
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// HealthResponse — ответ /api/health: сервис и автоматы бирж.
+type HealthResponse struct {
+	Status    string           `json:"status"` // ok | degraded (часть бирж отключена) | down (отключены все)
+	Exchanges []ExchangeHealth `json:"exchanges,omitempty"`
+	Cache     *CacheHealth     `json:"cache,omitempty"`
+}
+
+// ExchangeHealth — состояние автомата одной биржи.
+type ExchangeHealth struct {
+	Name      string `json:"name"`
+	State     string `json:"state"` // closed | open | half-open
+	Failures  int    `json:"failures"`
+	LastError string `json:"lastError,omitempty"`
+	OpenUntil string `json:"openUntil,omitempty"` // RFC3339
+}
+
+// CacheHealth — кэш стаканов и фоновый прогрев.
+type CacheHealth struct {
+	Hits             int64    `json:"hits"`
+	Shared           int64    `json:"shared"` // присоединились к идущей загрузке
+	Misses           int64    `json:"misses"`
+	Prefetches       int64    `json:"prefetches"`
+	HitRatio         float64  `json:"hitRatio"` // (hits+shared) / запросы пользователей
+	Hot              []string `json:"hot,omitempty"`
+	PrefetchInterval string   `json:"prefetchInterval,omitempty"`
+	LastPrefetch     string   `json:"lastPrefetch,omitempty"` // RFC3339
+	RateLimited      []string `json:"rateLimited,omitempty"`
+}
@@ -30,6 +30,11 @@ const dict = {
         usdt: 'USDT',
         calculating: 'Calculating…',
         resultsFor: 'Results for',
+        savingsVsBest: 'Savings vs best single',
+        planLink: 'Quote link',
+        planNotFound: 'Plan not found',
+        venuesUsed: 'Exchanges used',
+        vsOptimal: 'vs optimal',
     },
     ru: {
         buy: 'Купить',
@@ -59,6 +64,11 @@ const dict = {
         usdt: 'USDT',
         calculating: 'Расчёт…',
         resultsFor: 'Результаты для',
+        savingsVsBest: 'Выгода против лучшей одиночной',
+        planLink: 'Ссылка на расчёт',
+        planNotFound: 'Расчёт не найден',
+        venuesUsed: 'Задействованы биржи',
+        vsOptimal: 'против оптимального',
     }
 };
 
@@ -169,13 +179,29 @@ async function loadSymbols() {
     }
 }
 
+// Сценарии с сервера: id -> {title, description} по языкам
+let scenarioCatalog = {};
+
+async function loadScenarios() {
+    try {
+        const r = await fetch('/api/scenarios', { cache: 'no-store' });
+        if (!r.ok) throw new Error(`HTTP ${r.status}`);
+        const j = await r.json();
+        (Array.isArray(j?.scenarios) ? j.scenarios : []).forEach(sc => { scenarioCatalog[sc.id] = sc; });
+    } catch {
+        // остаются встроенные названия из dict
+    }
+}
+
 /* ========== Вспомогательные ========== */
 function scenarioTitle(s) {
-    const t = dict[currentLang];
-    if (s === 'best_single') return t.best_single;
-    if (s === 'equal_split') return t.equal_split;
-    if (s === 'optimal')     return t.optimal;
-    return s || '';
+    const fromServer = scenarioCatalog[s]?.title?.[currentLang];
+    if (fromServer) return fromServer;
+    return dict[currentLang][s] || s || '';
+}
+
+function scenarioDescription(s) {
+    return scenarioCatalog[s]?.description?.[currentLang] || '';
 }
 
 /* ========== Рендер карточки сценария ========== */
@@ -217,18 +243,19 @@ function buildSummaryHTML(j){
         : qtyCOINTerse(Number(j.totalCost || 0));
     const unitStr = spendUnits;
 
-    const descriptions = {
-        best_single: currentLang === 'ru'
-            ? 'Вся сумма уходит на одну биржу с наилучшей ценой'
-            : 'All funds go to the single exchange with the best price',
-        equal_split: currentLang === 'ru'
-            ? 'Сумма делится равными частями между всеми биржами'
-            : 'Funds are split equally across all exchanges',
-        optimal: currentLang === 'ru'
-            ? 'Сумма распределяется оптимально между биржами для лучшей цены'
-            : 'Funds are distributed optimally across exchanges for best execution',
-    };
-    const descr = descriptions[j.scenario] || '';
+    const descr = scenarioDescription(j.scenario);
+
+    // Выгода против best_single (приходит из /api/compare)
+    // min_venues: выбранные биржи и цена отказа от остальных
+    const venuesBlock = (Array.isArray(j.venues) ? j.venues : []).map(v =>
+        `<div><strong>${t.venuesUsed} (${v.coin}):</strong> ${(v.exchanges || []).join(', ')} — ${t.vsOptimal}: ${moneyUSDT(v.costDiffUsdt || 0)} ${t.usdt} (${Number(v.costDiffBps || 0).toFixed(1)} bps)</div>`
+    ).join('');
+
+    const savingsBlock = (j.scenario !== 'best_single' && typeof j.savingsAbs === 'number' && j.rank > 0)
+        ? `<div><strong>${t.savingsVsBest}:</strong> ${
+            String(j.savingsUnit || '').toUpperCase() === 'USDT' ? moneyUSDT(j.savingsAbs) : qtyCOINTerse(j.savingsAbs)
+        } ${j.savingsUnit || ''} (${Number(j.savingsBps || 0).toFixed(1)} bps)</div>`
+        : '';
 
     return `
     <h2>${t.summary} — ${scenarioTitle(j.scenario || '')}</h2>
@@ -245,6 +272,9 @@ function buildSummaryHTML(j){
         <div><strong>${t.avgPrice}:</strong> ${avgNum} ${avgUnits}</div>
         <div><strong>${t.assetsNoFees}:</strong> ${assetsNoFeesNum} ${unitStr}</div>
         <div><strong>${t.totalToPay}:</strong> ${totalToPayNum} ${unitStr}</div>
+        ${savingsBlock}
+        ${venuesBlock}
+        ${j.id ? `<div><strong>${t.planLink}:</strong> <a href="?plan=${encodeURIComponent(j.id)}">${j.id}</a></div>` : ''}
       </div>
     </div>
   `;
@@ -342,6 +372,21 @@ function buildScenarioPanel(j){
   `;
 }
 
+/* ========== Сохранённый расчёт по ссылке ?plan=<id> ========== */
+async function loadSharedPlan(cmp){
+    const id = new URLSearchParams(location.search).get('plan');
+    if (!id || !cmp) return;
+    try {
+        const r = await fetch(`/api/plans/${encodeURIComponent(id)}`, { cache: 'no-store' });
+        if (!r.ok) throw new Error(`HTTP ${r.status}`);
+        const j = await r.json();
+        const title = `<h2 class="muted" style="margin:8px 0 0 2px;">${dict[currentLang].resultsFor}: ${j.plan?.base || ''}/${j.plan?.quote || ''} (${j.id})</h2>`;
+        cmp.innerHTML = title + buildScenarioPanel(j.plan || {});
+    } catch {
+        cmp.innerHTML = `<section class="card"><div class="muted">${dict[currentLang].planNotFound}: ${id}</div></section>`;
+    }
+}
+
 /* ========== Инициализация ========== */
 document.addEventListener('DOMContentLoaded', () => {
     const y = $('footer-year'); if (y) y.textContent = new Date().getFullYear();
@@ -362,11 +407,14 @@ document.addEventListener('DOMContentLoaded', () => {
 
     checkHealth();
     loadSymbols();
+    loadScenarios();
 
     const form = $('plan-form');
     const cmp  = $('comparisons');
     const btn  = $('calc-btn');
 
+    loadSharedPlan(cmp);
+
     form?.addEventListener('submit', async (e) => {
         e.preventDefault();
         if (!btn || !cmp) return;
@@ -378,22 +426,21 @@ document.addEventListener('DOMContentLoaded', () => {
 
         cmp.innerHTML = `<section class="card"><div class="muted">${dict[currentLang].calculating}</div></section>`;
 
-        const scenarios = ['best_single', 'equal_split', 'optimal'];
         try {
-            const fetchOne = (scenario) =>
-                fetch('/api/plan', {
-                    method: 'POST',
-                    headers: { 'Content-Type': 'application/json' },
-                    body: JSON.stringify({ base, quote, amount, scenario }),
-                }).then(async r => {
-                    const text = await r.text();
-                    if (!r.ok) throw new Error(text || `HTTP ${r.status}`);
-                    const j = JSON.parse(text);
-                    if (j && j.error) throw new Error(j.error);
-                    return j;
-                });
-
-            const results = await Promise.all(scenarios.map(fetchOne));
+            // Один запрос: сервер тянет стаканы один раз и считает все сценарии
+            const r = await fetch('/api/compare', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ base, quote, amount }),
+            });
+            const text = await r.text();
+            if (!r.ok) throw new Error(text || `HTTP ${r.status}`);
+            const j = JSON.parse(text);
+            if (j && j.error) throw new Error(j.error);
+
+            const all = Array.isArray(j?.results) ? j.results : [];
+            const results = all.filter(x => !x.error);
+            if (!results.length && all.length) throw new Error(all[0].error);
             const title = `<h2 class="muted" style="margin:8px 0 0 2px;">${dict[currentLang].resultsFor}: ${base}/${quote}</h2>`;
             cmp.innerHTML = title + results.map(buildScenarioPanel).join('');
         } catch (err) {
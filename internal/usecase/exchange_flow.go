@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,16 +9,15 @@ import (
 	"time"
 
 	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
 	"cryptobot/internal/transport/cli"
+	"cryptobot/internal/usecase/planner"
 	"cryptobot/internal/usecase/scenario"
 )
 
 // --- локальные интерфейсы ---
 
-type strategy interface {
-	Name() string
-	Run(in scenario.Inputs) scenario.Result
-}
+type strategy = scenario.Strategy
 
 type presenterLite interface {
 	Infof(format string, args ...any)
@@ -25,21 +25,28 @@ type presenterLite interface {
 	ShowOrderBookSummary(ob *domain.OrderBook)
 	RenderScenario(title string, r scenario.Result)
 	RenderComparisons(results map[string]scenario.Result)
+	RenderRouteStage(title string, st planner.Stage)
+	RenderRouteComparisons(from, to string, rows []cli.RouteSummary)
 }
 
-type snap struct {
-	name string
-	res  scenario.Result
+// RoutePlanner — расчёт обмена монета → USDT → монета (planner.Service).
+type RoutePlanner interface {
+	Compare(ctx context.Context, in planner.Request) (planner.CompareResult, error)
 }
 
-func Run(cfg domain.Config, exchanges []domain.Exchange) error {
+func Run(cfg domain.Config, exchanges []domain.Exchange, plans RoutePlanner) error {
 	pr := cli.NewCLIPresenter()
-	strategies := []strategy{
-		scenario.BestSingle{},
-		scenario.EqualSplit{},
-		scenario.Optimal{},
+	return runCore(cfg, exchanges, pr, registeredStrategies(), plans)
+}
+
+// registeredStrategies — все сценарии из реестра в порядке показа.
+func registeredStrategies() []strategy {
+	all := scenario.All()
+	out := make([]strategy, 0, len(all))
+	for _, e := range all {
+		out = append(out, e.Strategy)
 	}
-	return runCore(cfg, exchanges, pr, strategies)
+	return out
 }
 
 type fetchRes struct {
@@ -49,29 +56,50 @@ type fetchRes struct {
 	dur  time.Duration
 }
 
+// pairSymbols — направление одноэтапной сделки и нужный стакан; symbols[0] — стакан,
+// по которому считается сделка. Обмен монета → монета стаканы берёт у planner.Service.
+func pairSymbols(params cli.InputParams) (scenario.Direction, []string) {
+	if params.Action == "sell" {
+		return scenario.Sell, []string{strings.ToUpper(params.LeftCoinName) + "USDT"}
+	}
+	return scenario.Buy, []string{strings.ToUpper(params.RightCoinName) + "USDT"}
+}
+
+// fetchAll — параллельный сбор стаканов со всех бирж (ключ — имя биржи).
+func fetchAll(cfg domain.Config, exchanges []domain.Exchange, symbols []string) map[string]fetchRes {
+	results := make(map[string]fetchRes, len(exchanges))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(exchanges))
+	for _, ex := range exchanges {
+		ex := ex
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			// limit всегда = 0 → максимальная глубина
+			obs, err := ex.GetMultipleOrderBooks(symbols, 0, time.Duration(cfg.DelayMS)*time.Millisecond)
+			mu.Lock()
+			results[ex.Name()] = fetchRes{name: ex.Name(), obs: obs, err: err, dur: time.Since(start)}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
 func runCore(
 	cfg domain.Config,
 	exchanges []domain.Exchange,
 	pr presenterLite,
 	strategies []strategy,
+	plans RoutePlanner,
 ) error {
 	params := cli.GetInteractiveParams()
 
-	left := strings.ToUpper(params.LeftCoinName)
 	right := strings.ToUpper(params.RightCoinName)
 
-	dir := scenario.Buy
-	if params.Action == "sell" {
-		dir = scenario.Sell
-	}
-
-	var symbol string
-	if dir == scenario.Buy {
-		symbol = right + "USDT"
-	} else {
-		symbol = left + "USDT"
-	}
-	symbols := []string{symbol}
+	dir, symbols := pairSymbols(params)
+	symbol := symbols[0]
 
 	exNames := make([]string, 0, len(exchanges))
 	for _, ex := range exchanges {
@@ -80,29 +108,20 @@ func runCore(
 	pr.Infof("=== Крипто-биржи Монитор ===\n")
 	pr.Infof("Доступные биржи: %v\n", exNames)
 
+	if params.Action == "swap" {
+		// стаканы тянет planner.Service: показываем те, по которым он считал, без второго запроса
+		return runRoute(pr, plans, params)
+	}
+
 	now := time.Now()
 	const maxStale = 10 * time.Second
 
-	// Параллельный сбор стаканов
-	results := make(map[string]fetchRes, len(exchanges))
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	wg.Add(len(exchanges))
-	for _, ex := range exchanges {
-		ex := ex
-		go func() {
-			defer wg.Done()
-			start := time.Now()
-			// limit всегда = 0 → максимальная глубина
-			obs, err := ex.GetMultipleOrderBooks(symbols, 0, time.Duration(cfg.DelayMS)*time.Millisecond)
-			mu.Lock()
-			results[ex.Name()] = fetchRes{name: ex.Name(), obs: obs, err: err, dur: time.Since(start)}
-			mu.Unlock()
-		}()
-	}
-	wg.Wait()
+	results := fetchAll(cfg, exchanges, symbols)
 
-	allByEx := map[string]*domain.OrderBook{}
+	books := make(map[string]map[string]*domain.OrderBook, len(symbols))
+	for _, sym := range symbols {
+		books[sym] = map[string]*domain.OrderBook{}
+	}
 	for _, ex := range exchanges {
 		name := ex.Name()
 		res := results[name]
@@ -117,18 +136,16 @@ func runCore(
 			continue
 		}
 		pr.Infof("Успешно получено стаканов: %d\n", len(res.obs))
-		if ob, ok := res.obs[symbol]; ok && ob != nil {
-			// проверка на устаревание
-			var t time.Time
-			if ob.Timestamp > 1e12 {
-				t = time.UnixMilli(ob.Timestamp)
-			} else {
-				t = time.Unix(ob.Timestamp, 0)
+		for _, sym := range symbols {
+			ob, ok := res.obs[sym]
+			if !ok || ob == nil {
+				continue
 			}
-			if time.Since(t) > maxStale {
-				pr.Warnf("Данные %s:%s устарели на ~%ds\n", name, symbol, int(time.Since(t).Seconds()))
+			// проверка на устаревание: такие стаканы сценарии пропустят
+			if t := ob.Time(); !t.IsZero() && time.Since(t) > maxStale {
+				pr.Warnf("Данные %s:%s устарели на ~%ds и не участвуют в расчёте\n", name, sym, int(time.Since(t).Seconds()))
 			}
-			allByEx[name] = ob
+			books[sym][name] = ob
 			pr.ShowOrderBookSummary(ob)
 		}
 	}
@@ -138,35 +155,74 @@ func runCore(
 		Direction:  dir,
 		Symbol:     symbol,
 		Right:      right,
-		Amount:     params.LeftCoinVolume,
-		OrderBooks: allByEx,
+		Amount:     money.FromFloat(params.LeftCoinVolume),
+		OrderBooks: books[symbol],
 		Now:        now,
 		MaxStale:   maxStale,
 	}
 
-	// Запуск стратегий
+	// Запуск стратегий и печать каждого сценария
 	resultsMap := make(map[string]scenario.Result, len(strategies))
-	var snaps []snap
 	for _, st := range strategies {
 		res := st.Run(in)
-		snaps = append(snaps, snap{name: st.Name(), res: res})
 		resultsMap[st.Name()] = res
-	}
-
-	// Печать каждого сценария
-	for _, sn := range snaps {
-		title := fmt.Sprintf("%s", sn.name)
-		pr.RenderScenario(title, sn.res)
+		pr.RenderScenario(st.Name(), res)
 	}
 
 	// Сравнение сценариев одной таблицей
-	// Для наглядности отсортируем: BUY — по возрастанию VWAP; SELL — по убыванию VWAP.
-	if dir == scenario.Buy {
-		sort.Slice(snaps, func(i, j int) bool { return snaps[i].res.AveragePrice < snaps[j].res.AveragePrice })
-	} else {
-		sort.Slice(snaps, func(i, j int) bool { return snaps[i].res.AveragePrice > snaps[j].res.AveragePrice })
-	}
 	pr.RenderComparisons(resultsMap)
 
 	return nil
 }
+
+// runRoute — обмен монета → монета через USDT: считает planner.Service с теми же
+// комиссиями, проверками стаканов и политикой устаревания, что и веб;
+// печатаются стаканы расчёта, оба этапа каждого сценария и сводное сравнение.
+func runRoute(pr presenterLite, plans RoutePlanner, params cli.InputParams) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cli.DefaultTimeout)
+	defer cancel()
+
+	res, err := plans.Compare(ctx, params.PlanRequest())
+	if err != nil {
+		return err
+	}
+	for _, ob := range routeBooks(res) {
+		pr.ShowOrderBookSummary(ob)
+	}
+	// ошибки бирж, устаревшие и не прошедшие проверку стаканы
+	for _, d := range res.Diagnostics {
+		pr.Warnf("Диагностика: %s\n", d)
+	}
+	for _, it := range res.Items {
+		name := cli.ScenarioName(it.Scenario)
+		if it.Error != "" {
+			pr.Warnf("\n%s: %s\n", name, it.Error)
+			continue
+		}
+		sell, buy := it.Stages[0], it.Stages[1]
+		pr.RenderRouteStage(fmt.Sprintf("%s — этап 1: %s → USDT", name, sell.Coin), sell)
+		pr.RenderRouteStage(fmt.Sprintf("%s — этап 2: USDT → %s", name, buy.Coin), buy)
+	}
+	pr.RenderRouteComparisons(res.Quote, res.Base, cli.RouteSummaries(res))
+	return nil
+}
+
+// routeBooks — стаканы, по которым planner.Service посчитал обмен: сначала
+// продаваемая монета, затем покупаемая, внутри — по имени биржи.
+func routeBooks(res planner.CompareResult) []*domain.OrderBook {
+	var out []*domain.OrderBook
+	for _, coin := range []string{res.Quote, res.Base} {
+		bs := append([]planner.Book(nil), res.Books[coin]...)
+		sort.Slice(bs, func(i, j int) bool { return bs[i].Exchange < bs[j].Exchange })
+		for _, b := range bs {
+			ob := &domain.OrderBook{Symbol: coin + "USDT", Exchange: b.Exchange, Asks: b.Asks, Bids: b.Bids}
+			if t := b.Timestamp; !t.IsZero() {
+				ob.Timestamp = t.UnixMilli()
+			} else if !b.ReceivedAt.IsZero() {
+				ob.Timestamp = b.ReceivedAt.UnixMilli() // биржа время не сообщает
+			}
+			out = append(out, ob)
+		}
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/transport/cli"
+	"cryptobot/internal/usecase/planner"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// fakeRoute отдаёт готовый результат сравнения и считает вызовы.
+type fakeRoute struct {
+	res   planner.CompareResult
+	calls int
+}
+
+func (f *fakeRoute) Compare(_ context.Context, _ planner.Request) (planner.CompareResult, error) {
+	f.calls++
+	return f.res, nil
+}
+
+// recPresenter запоминает показанные стаканы и предупреждения.
+type recPresenter struct {
+	books []string
+	warns []string
+}
+
+func (p *recPresenter) Infof(string, ...any) {}
+func (p *recPresenter) Warnf(format string, args ...any) {
+	p.warns = append(p.warns, fmt.Sprintf(format, args...))
+}
+func (p *recPresenter) RenderScenario(string, scenario.Result)                    {}
+func (p *recPresenter) RenderComparisons(map[string]scenario.Result)              {}
+func (p *recPresenter) RenderRouteStage(string, planner.Stage)                    {}
+func (p *recPresenter) RenderRouteComparisons(string, string, []cli.RouteSummary) {}
+func (p *recPresenter) ShowOrderBookSummary(ob *domain.OrderBook) {
+	p.books = append(p.books, fmt.Sprintf("%s:%s@%d", ob.Exchange, ob.Symbol, ob.Timestamp))
+}
+
+func TestRunRouteShowsPlannerBooks(t *testing.T) {
+	ts := time.UnixMilli(1767323045000)
+	route := &fakeRoute{res: planner.CompareResult{
+		Base: "BTC", Quote: "ETH",
+		Diagnostics: []string{"okx:err:timeout"},
+		Books: map[string][]planner.Book{
+			"ETH": {{Exchange: "okx", ReceivedAt: ts}, {Exchange: "bybit", Timestamp: ts}},
+			"BTC": {{Exchange: "gate", Timestamp: ts.Add(time.Second)}},
+		},
+	}}
+	pr := &recPresenter{}
+	params := cli.InputParams{Action: "swap", LeftCoinName: "ETH", LeftCoinVolume: 1, RightCoinName: "BTC"}
+	if err := runRoute(pr, route, params); err != nil {
+		t.Fatal(err)
+	}
+	if route.calls != 1 {
+		t.Errorf("Compare calls = %d, want 1", route.calls)
+	}
+	want := []string{"bybit:ETHUSDT@1767323045000", "okx:ETHUSDT@1767323045000", "gate:BTCUSDT@1767323046000"}
+	if fmt.Sprint(pr.books) != fmt.Sprint(want) {
+		t.Errorf("books = %v, want %v", pr.books, want)
+	}
+	if len(pr.warns) != 1 {
+		t.Errorf("warnings = %q, want the exchange error", pr.warns)
+	}
+}
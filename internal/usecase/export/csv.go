@@ -0,0 +1,35 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// writeCSV — разделитель «;», т.к. числа в русском формате с десятичной запятой.
+func writeCSV(w io.Writer, doc document) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+
+	_ = cw.Write([]string{doc.title})
+	for _, s := range doc.sections {
+		_ = cw.Write(nil)
+		_ = cw.Write([]string{s.title})
+		for _, kv := range s.meta {
+			_ = cw.Write([]string{kv[0], kv[1]})
+		}
+		_ = cw.Write(nil)
+		_ = cw.Write(s.header)
+		for _, row := range s.rows {
+			rec := make([]string, len(row))
+			for i, c := range row {
+				rec[i] = c.text
+			}
+			_ = cw.Write(rec)
+		}
+		for _, n := range s.notes {
+			_ = cw.Write([]string{"Note", n})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
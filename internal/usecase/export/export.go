@@ -0,0 +1,226 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/format"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
+)
+
+// Format — формат выгрузки.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	PDF  Format = "pdf"
+)
+
+// ParseFormat принимает "csv", "xlsx", "pdf" (регистр и точка в начале не важны).
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), ".")); f {
+	case CSV, XLSX, PDF:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q (csv, xlsx, pdf)", s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// ====== Нейтральная модель документа: заголовок, реквизиты, таблица, примечания ======
+
+type cell struct {
+	text  string          // отформатированное значение (CSV/PDF)
+	num   decimal.Decimal // сырое число (XLSX)
+	isNum bool
+}
+
+func txt(s string) cell { return cell{text: s} }
+
+func num(v float64, decimals int) cell {
+	return cell{text: format.FloatRU(v, decimals), num: decimal.NewFromFloat(v), isNum: true}
+}
+
+// dec — десятичная сумма или цена: в XLSX уходит без перевода во float64.
+func dec(v decimal.Decimal, places int32) cell {
+	return cell{text: format.DecimalRU(v, places), num: v, isNum: true}
+}
+
+// amount — сумма в активе с его точностью; rate — курс со значащими цифрами.
+func amount(v decimal.Decimal, asset string) cell { return dec(v, money.Precision(asset)) }
+func rate(v decimal.Decimal) cell                 { return dec(v, money.RatePlaces(v)) }
+
+type section struct {
+	title  string
+	meta   [][2]string
+	header []string
+	rows   [][]cell
+	notes  []string
+}
+
+type document struct {
+	title    string
+	sections []section
+}
+
+// Plan пишет план одного сценария в w.
+func Plan(w io.Writer, f Format, res planner.Result) error {
+	doc := document{title: fmt.Sprintf("Execution plan %s/%s", res.Base, res.Quote)}
+	doc.sections = append(doc.sections, planSection(res, ""))
+	return write(w, f, doc)
+}
+
+// Compare пишет сравнение сценариев: сводную таблицу и распределение каждого сценария.
+func Compare(w io.Writer, f Format, res planner.CompareResult) error {
+	doc := document{title: fmt.Sprintf("Scenario comparison %s/%s", res.Base, res.Quote)}
+
+	recv, pay := res.Base, res.Quote // Generated всегда в BASE, траты — в QUOTE
+	summary := section{
+		title: "Summary",
+		meta: [][2]string{
+			{"Pair", res.Base + "/" + res.Quote},
+			{"Amount", format.DecimalRU(money.FromFloat(res.Amount), money.Precision(pay)) + " " + pay},
+			{"VWAP unit", priceUnit(res.Base, res.Quote)},
+			{"Savings", "vs best_single, in " + savingsUnit(res)},
+			{"Generated at", res.GeneratedAt},
+		},
+		// короткие заголовки: таблица должна помещаться в ширину PDF-страницы
+		header: []string{"#", "Scenario", "VWAP", "Spent " + pay, "Received " + recv, "Unspent " + pay, "Savings", "bps"},
+		notes:  res.Diagnostics,
+	}
+	for _, it := range res.Items {
+		rank := "-"
+		if it.Rank > 0 {
+			rank = strconv.Itoa(it.Rank)
+		}
+		if it.Error != "" {
+			summary.rows = append(summary.rows, []cell{txt(rank), txt(it.Scenario), txt(it.Error), txt(""), txt(""), txt(""), txt(""), txt("")})
+			continue
+		}
+		summary.rows = append(summary.rows, []cell{
+			txt(rank),
+			txt(it.Scenario),
+			rate(it.VWAP.Decimal),
+			amount(it.TotalCost.Decimal, pay),
+			amount(it.Generated.Decimal, recv),
+			amount(it.Unspent.Decimal, pay),
+			amount(it.SavingsAbs.Decimal, it.SavingsUnit),
+			num(it.SavingsBps, 1),
+		})
+	}
+	doc.sections = append(doc.sections, summary)
+
+	for _, it := range res.Items {
+		if it.Error != "" {
+			continue
+		}
+		doc.sections = append(doc.sections, planSection(it.Result, "Allocation: "+it.Scenario))
+	}
+	return write(w, f, doc)
+}
+
+func planSection(res planner.Result, title string) section {
+	if title == "" {
+		title = "Allocation: " + res.Scenario
+	}
+	recv, pay := res.Base, res.Quote
+	legUnit := legUnit(res.Base, res.Quote)
+
+	s := section{
+		title: title,
+		meta: [][2]string{
+			{"Pair", res.Base + "/" + res.Quote},
+			{"Scenario", res.Scenario},
+			{"Spent", amount(res.TotalCost.Decimal, pay).text + " " + pay},
+			{"Received", amount(res.Generated.Decimal, recv).text + " " + recv},
+			{"VWAP", rate(res.VWAP.Decimal).text + " " + priceUnit(res.Base, res.Quote)},
+		},
+		header: []string{"Exchange", "Amount (" + legUnit + ")", "Price (USDT per 1 " + legUnit + ")", "Total (USDT)", "Depth used (%)"},
+		notes:  res.Diagnostics,
+	}
+	if res.Unspent.IsPositive() {
+		s.meta = append(s.meta, [2]string{"Unspent", amount(res.Unspent.Decimal, pay).text + " " + pay})
+	}
+	if res.RiskAdjustedVWAP.IsPositive() {
+		s.meta = append(s.meta, [2]string{"Risk-adjusted VWAP", rate(res.RiskAdjustedVWAP.Decimal).text + " " + priceUnit(res.Base, res.Quote)})
+	}
+	if res.Confidence != "" {
+		s.meta = append(s.meta, [2]string{"Confidence", res.Confidence})
+	}
+	for _, v := range res.Venues {
+		s.meta = append(s.meta, [2]string{"Venues " + v.Coin, fmt.Sprintf("%s (tolerance %s bps, vs optimal %s USDT / %s bps)",
+			strings.Join(v.Exchanges, ", "), format.FloatRU(v.ToleranceBps, 1), amount(v.CostDiffUSDT.Decimal, "USDT").text, format.FloatRU(v.CostDiffBps, 2))})
+	}
+	s.meta = append(s.meta, [2]string{"Generated at", res.GeneratedAt})
+	if res.ID != "" {
+		s.meta = append(s.meta, [2]string{"Plan ID", res.ID})
+	}
+
+	var totalQty, totalUSDT decimal.Decimal
+	for _, l := range res.Legs {
+		usdt := money.Amount(l.Amount.Mul(l.Price.Decimal), "USDT")
+		totalQty = totalQty.Add(l.Amount.Decimal)
+		totalUSDT = totalUSDT.Add(usdt)
+		s.rows = append(s.rows, []cell{txt(l.Exchange), amount(l.Amount.Decimal, legUnit), rate(l.Price.Decimal), amount(usdt, "USDT"), num(l.DepthUsedPct, 1)})
+	}
+	s.rows = append(s.rows, []cell{txt("Total"), amount(totalQty, legUnit), txt(""), amount(totalUSDT, "USDT"), txt("")})
+	return s
+}
+
+func savingsUnit(res planner.CompareResult) string {
+	for _, it := range res.Items {
+		if it.SavingsUnit != "" {
+			return it.SavingsUnit
+		}
+	}
+	return "USDT"
+}
+
+// legUnit — в чём считается количество на ножке: при продаже за USDT — QUOTE, иначе BASE.
+func legUnit(base, quote string) string {
+	if isUSDT(base) {
+		return quote
+	}
+	return base
+}
+
+// priceUnit — единицы VWAP в planner.Result.
+func priceUnit(base, quote string) string {
+	switch {
+	case isUSDT(quote):
+		return "USDT per 1 " + base
+	case isUSDT(base):
+		return "USDT per 1 " + quote
+	}
+	return base + "/" + quote
+}
+
+func isUSDT(s string) bool { return strings.EqualFold(strings.TrimSpace(s), "USDT") }
+
+func write(w io.Writer, f Format, doc document) error {
+	switch f {
+	case CSV:
+		return writeCSV(w, doc)
+	case XLSX:
+		return writeXLSX(w, doc)
+	case PDF:
+		return writePDF(w, doc)
+	}
+	return fmt.Errorf("unsupported export format %q", f)
+}
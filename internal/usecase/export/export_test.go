@@ -0,0 +1,225 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
+)
+
+func d(s string) money.Number { return money.Num(decimal.RequireFromString(s)) }
+
+// longNote — длиннее строки PDF; с кириллицей, которой нет в Courier.
+const longNote = "okx:stale:excluded:BTC age=12.5s > 10s; стакан устарел — биржа исключена из расчёта, повторите запрос позже"
+
+var testResult = planner.Result{
+	ID: "p1", Scenario: "optimal", Base: "BTC", Quote: "USDT",
+	VWAP: d("100000.5"), TotalCost: d("150000.75"), Generated: d("1.5"),
+	Legs: []planner.Leg{
+		{Exchange: "binance", Amount: d("1"), Price: d("100000"), DepthUsedPct: 40},
+		{Exchange: "okx", Amount: d("0.5"), Price: d("100001.5"), DepthUsedPct: 100},
+	},
+	Diagnostics: []string{longNote},
+	GeneratedAt: "12:00 01.03.2026",
+}
+
+func export(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Plan(&buf, f, testResult); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPlanCSV(t *testing.T) {
+	r := csv.NewReader(bytes.NewReader(export(t, CSV)))
+	r.Comma = ';'
+	r.FieldsPerRecord = -1
+	recs, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Execution plan BTC/USDT"},
+		{"Allocation: optimal"},
+		{"Pair", "BTC/USDT"},
+		{"Spent", "150.000,75 USDT"},
+		{"Exchange", "Amount (BTC)", "Price (USDT per 1 BTC)", "Total (USDT)", "Depth used (%)"},
+		{"binance", "1,00000000", "100.000,00", "100.000,00", "40,0"},
+		{"okx", "0,50000000", "100.001,50", "50.000,75", "100,0"},
+		{"Total", "1,50000000", "", "150.000,75", ""},
+		{"Note", longNote}, // в CSV текст как есть, в UTF-8
+	}
+	for _, w := range want {
+		if !slices.ContainsFunc(recs, func(rec []string) bool { return slices.Equal(rec, w) }) {
+			t.Errorf("no record %q in\n%q", w, recs)
+		}
+	}
+}
+
+func TestPlanXLSX(t *testing.T) {
+	data := export(t, XLSX)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], _ = io.ReadAll(rc)
+		_ = rc.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		body, ok := parts[name]
+		if !ok {
+			t.Errorf("missing part %s", name)
+			continue
+		}
+		if err := xml.Unmarshal(body, new(struct{})); err != nil {
+			t.Errorf("%s: invalid XML: %v", name, err)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	cells := map[string]string{} // ссылка → значение; числа без «t»
+	for _, row := range sheet.Rows {
+		for _, c := range row.Cells {
+			if c.Type == "inlineStr" {
+				cells[c.Ref] = c.Inline
+			} else {
+				cells[c.Ref] = "=" + c.Value
+			}
+		}
+	}
+	ref := func(col, text string) string { // ячейка справа от подписи text в колонке col
+		for k, v := range cells {
+			if v == text && strings.HasPrefix(k, col) {
+				return k[len(col):]
+			}
+		}
+		return ""
+	}
+	row := ref("A", "binance")
+	if row == "" {
+		t.Fatalf("no binance row in %v", cells)
+	}
+	// числа — числами и без округления форматом
+	if got := cells["B"+row] + " " + cells["C"+row] + " " + cells["E"+row]; got != "=1 =100000 =40" {
+		t.Errorf("binance row = %q", got)
+	}
+	if got := cells["B"+ref("A", "Note")]; got != longNote {
+		t.Errorf("note = %q", got)
+	}
+}
+
+func TestPlanPDF(t *testing.T) {
+	data := export(t, PDF)
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("bad header or trailer")
+	}
+
+	// startxref указывает на таблицу xref, а каждая её запись — на «N 0 obj»
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at xref", xref)
+	}
+	var size int
+	if _, err := fmt.Sscanf(string(data[xref:]), "xref\n0 %d\n", &size); err != nil {
+		t.Fatal(err)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != size-1 {
+		t.Fatalf("xref entries = %d, want %d", len(entries), size-1)
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", i+1, off, data[off:min(off+12, len(data))])
+		}
+	}
+	if !bytes.Contains(data, []byte(fmt.Sprintf("/Size %d ", size))) {
+		t.Errorf("trailer /Size does not match xref")
+	}
+
+	// длина потока совпадает с /Length
+	for _, s := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		if n, _ := strconv.Atoi(string(s[1])); n != len(s[2]) {
+			t.Errorf("stream /Length %d, actual %d", n, len(s[2]))
+		}
+	}
+
+	// строки текста: только ASCII, не шире страницы, кириллица транслитерирована
+	var text []string
+	for _, s := range regexp.MustCompile(`\((.*)\) '\n`).FindAllSubmatch(data, -1) {
+		text = append(text, string(s[1]))
+	}
+	all := strings.Join(text, "\n")
+	for _, l := range text {
+		if len(l) > pdfMaxChars {
+			t.Errorf("line longer than %d: %q", pdfMaxChars, l)
+		}
+		if strings.ContainsRune(l, '?') {
+			t.Errorf("unprintable characters in %q", l)
+		}
+	}
+	for _, want := range []string{"Note: okx:stale:excluded:BTC", "stakan ustarel - birzha", "iskliuchena iz rascheta"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("PDF text has no %q:\n%s", want, all)
+		}
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "short", want: []string{"short"}},
+		{in: "aaaa bbbb cccc", want: []string{"aaaa bbbb", "  cccc"}},
+		{in: "aaaa bbbbb cc", want: []string{"aaaa", "  bbbbb", "  cc"}},
+		{in: "aaaaaaaaaaaa", want: []string{"aaaaaaaaa", "  aaa"}},
+	}
+	for _, tt := range tests {
+		if got := wrapLine(tt.in, 9, "  "); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	if got := pdfText("Щука «Ёж» — №1 ≈ 5\u00a0%"); got != `Shchuka "Ezh" - No.1 ~ 5 %` {
+		t.Errorf("pdfText = %q", got)
+	}
+}
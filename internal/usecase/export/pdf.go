@@ -0,0 +1,204 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Параметры страницы: A4, моноширинный Courier — таблицы выравниваются пробелами.
+const (
+	pdfPageW     = 595
+	pdfPageH     = 842
+	pdfMargin    = 40
+	pdfFontSize  = 9
+	pdfLeading   = 12
+	pdfMaxChars  = (pdfPageW - 2*pdfMargin) * 10 / (6 * pdfFontSize) // ширина Courier — 0.6 кегля
+	pdfPageLines = (pdfPageH - 2*pdfMargin) / pdfLeading
+)
+
+// writePDF — лист котировки: реквизиты, таблицы ножек, примечания.
+// Стандартный шрифт Courier не содержит кириллицы, поэтому русский текст
+// (диагностика, ошибки сценариев) транслитерируется, а длинные строки переносятся.
+func writePDF(w io.Writer, doc document) error {
+	var lines []string
+	add := func(s, indent string) { lines = append(lines, wrapLine(pdfText(s), pdfMaxChars, indent)...) }
+
+	add(doc.title, "")
+	lines = append(lines, strings.Repeat("=", min(len(pdfText(doc.title)), pdfMaxChars)), "")
+	for _, s := range doc.sections {
+		add(s.title, "")
+		lines = append(lines, strings.Repeat("-", min(len(pdfText(s.title)), pdfMaxChars)))
+		for _, kv := range s.meta {
+			add(fmt.Sprintf("%-14s %s", kv[0]+":", kv[1]), strings.Repeat(" ", 15))
+		}
+		lines = append(lines, "")
+		for _, l := range tableLines(s.header, s.rows) {
+			add(l, "  ")
+		}
+		for _, n := range s.notes {
+			add("Note: "+n, "      ")
+		}
+		lines = append(lines, "")
+	}
+
+	var pages [][]string
+	for len(lines) > 0 {
+		n := min(len(lines), pdfPageLines)
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+
+	// объекты: 1 — каталог, 2 — дерево страниц, 3 — шрифт, далее пары (страница, поток)
+	var objs []string
+	objs = append(objs, "<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	var kids []string
+	for _, pg := range pages {
+		pageID := len(objs) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageH-pdfMargin)
+		for _, l := range pg {
+			fmt.Fprintf(&content, "(%s) '\n", pdfEscape(l))
+		}
+		content.WriteString("ET")
+
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageW, pdfPageH, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objs[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, o := range objs {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// tableLines выравнивает таблицу по ширине колонок (числа — вправо).
+func tableLines(header []string, rows [][]cell) []string {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = len([]rune(h))
+	}
+	for _, r := range rows {
+		for i, c := range r {
+			if i < len(widths) && len([]rune(c.text)) > widths[i] {
+				widths[i] = len([]rune(c.text))
+			}
+		}
+	}
+
+	render := func(cells []cell) string {
+		parts := make([]string, len(widths))
+		for i := range widths {
+			var c cell
+			if i < len(cells) {
+				c = cells[i]
+			}
+			if c.isNum {
+				parts[i] = fmt.Sprintf("%*s", widths[i], c.text)
+			} else {
+				parts[i] = fmt.Sprintf("%-*s", widths[i], c.text)
+			}
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+
+	hdr := make([]cell, len(header))
+	for i, h := range header {
+		hdr[i] = txt(h)
+	}
+	out := []string{render(hdr)}
+	total := 0
+	for _, wd := range widths {
+		total += wd + 2
+	}
+	out = append(out, strings.Repeat("-", min(total-2, pdfMaxChars)))
+	for _, r := range rows {
+		out = append(out, render(r))
+	}
+	return out
+}
+
+// wrapLine переносит строку по словам в width символов; продолжения — с отступом
+// indent. Слово длиннее строки режется.
+func wrapLine(s string, width int, indent string) []string {
+	var out []string
+	for len(s) > width {
+		cut := strings.LastIndexByte(s[:width+1], ' ')
+		if cut <= len(indent) || strings.TrimSpace(s[:cut]) == "" {
+			cut = width
+		}
+		out = append(out, strings.TrimRight(s[:cut], " "))
+		s = indent + strings.TrimLeft(s[cut:], " ")
+	}
+	return append(out, s)
+}
+
+// pdfTranslit — кириллица латиницей (как в загранпаспорте) и типографские знаки в ASCII.
+var pdfTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+
+	'—': "-", '–': "-", '«': `"`, '»': `"`, '“': `"`, '”': `"`, '„': `"`, '‘': "'", '’': "'",
+	'…': "...", '≈': "~", '±': "+/-", '×': "x", '→': "->", '←': "<-", '≥': ">=", '≤': "<=",
+	'≠': "!=", '№': "No.", '·': "*", '•': "*", '\u00a0': " ", '\u202f': " ",
+}
+
+// pdfText приводит текст к ASCII, который есть в Courier.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 128 {
+			b.WriteRune(r)
+			continue
+		}
+		lower := unicode.ToLower(r)
+		t, ok := pdfTranslit[lower]
+		if !ok {
+			b.WriteByte('?')
+			continue
+		}
+		if lower != r && t != "" {
+			t = strings.ToUpper(t[:1]) + t[1:] // Ж → Zh
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
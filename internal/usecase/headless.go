@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
 	"cryptobot/internal/usecase/scenario"
 )
 
@@ -28,25 +29,15 @@ func RunHeadless(in HeadlessInput) ([]Snap, error) {
 		Direction:  in.Direction,
 		Symbol:     in.Symbol,
 		Right:      in.Right,
-		Amount:     in.Amount,
+		Amount:     money.FromFloat(in.Amount),
 		OrderBooks: in.OrderBooks,
 		Now:        in.Now,
 		MaxStale:   in.MaxStale,
 	}
 
-	strategies := []struct {
-		name string
-		run  func(scenario.Inputs) scenario.Result
-	}{
-		{"Сценарий #1 (лучшая биржа)", scenario.BestSingle{}.Run},
-		{"Сценарий #2 (равное распределение)", scenario.EqualSplit{}.Run},
-		{"Сценарий #3 (оптимальное распределение)", scenario.Optimal{}.Run},
-	}
-
 	var snaps []Snap
-	for _, st := range strategies {
-		res := st.run(inputs)
-		snaps = append(snaps, Snap{Name: st.name, Res: res})
+	for _, st := range registeredStrategies() {
+		snaps = append(snaps, Snap{Name: st.Name(), Res: st.Run(inputs)})
 	}
 
 	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
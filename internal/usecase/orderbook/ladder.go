@@ -0,0 +1,111 @@
+package orderbook
+
+import "math"
+
+// LadderRow — строка сводного стакана (уровень или бакет цен) с разбивкой по биржам.
+type LadderRow struct {
+	Price       float64            `json:"price"`
+	Qty         float64            `json:"qty"`
+	Notional    float64            `json:"notional"` // Qty * Price в USDT
+	CumQty      float64            `json:"cumQty"`   // накопленный объём от лучшей цены
+	CumNotional float64            `json:"cumNotional"`
+	ByExchange  map[string]float64 `json:"exchanges"` // объём уровня по биржам
+}
+
+// Bucketing — параметры группировки уровней. Tick имеет приоритет над Bps.
+type Bucketing struct {
+	Tick float64 // шаг цены в USDT
+	Bps  float64 // шаг цены в б.п. от лучшей цены стороны
+}
+
+// DepthBand — суммарная глубина в полосе ±Pct% от mid.
+type DepthBand struct {
+	Pct         float64 `json:"pct"`
+	AskQty      float64 `json:"askQty"`
+	AskNotional float64 `json:"askNotional"`
+	BidQty      float64 `json:"bidQty"`
+	BidNotional float64 `json:"bidNotional"`
+}
+
+// BuildLadder сворачивает отсортированные уровни (CombinedAsks/CombinedBids) в лестницу.
+// Для асков бакет округляется вверх, для бидов — вниз, чтобы цена бакета была худшей в нём.
+func BuildLadder(levels []Level, asks bool, b Bucketing) []LadderRow {
+	if len(levels) == 0 {
+		return nil
+	}
+	step := b.Tick
+	if step <= 0 && b.Bps > 0 {
+		step = levels[0].Price * b.Bps / 10000
+	}
+
+	var out []LadderRow
+	var cumQty, cumNotional float64
+	for _, lv := range levels {
+		price := lv.Price
+		if step > 0 {
+			if asks {
+				price = math.Ceil(lv.Price/step) * step
+			} else {
+				price = math.Floor(lv.Price/step) * step
+			}
+		}
+		if len(out) == 0 || out[len(out)-1].Price != price {
+			out = append(out, LadderRow{Price: price, ByExchange: map[string]float64{}})
+		}
+		row := &out[len(out)-1]
+		row.Qty += lv.Qty
+		row.Notional += lv.Qty * lv.Price
+		row.ByExchange[lv.Exchange] += lv.Qty
+
+		cumQty += lv.Qty
+		cumNotional += lv.Qty * lv.Price
+		row.CumQty = cumQty
+		row.CumNotional = cumNotional
+	}
+	return out
+}
+
+// DepthBands считает глубину асков/бидов в пределах ±pct% от mid.
+func DepthBands(asks, bids []Level, mid float64, pcts []float64) []DepthBand {
+	if mid <= 0 {
+		return nil
+	}
+	out := make([]DepthBand, 0, len(pcts))
+	for _, pct := range pcts {
+		if pct <= 0 {
+			continue
+		}
+		band := DepthBand{Pct: pct}
+		hi := mid * (1 + pct/100)
+		lo := mid * (1 - pct/100)
+		for _, a := range asks {
+			if a.Price > hi {
+				break
+			}
+			band.AskQty += a.Qty
+			band.AskNotional += a.Qty * a.Price
+		}
+		for _, d := range bids {
+			if d.Price < lo {
+				break
+			}
+			band.BidQty += d.Qty
+			band.BidNotional += d.Qty * d.Price
+		}
+		out = append(out, band)
+	}
+	return out
+}
+
+// Mid — середина между лучшими ценами; при одной стороне возвращает её лучшую цену.
+func Mid(asks, bids []Level) float64 {
+	switch {
+	case len(asks) > 0 && len(bids) > 0:
+		return (asks[0].Price + bids[0].Price) / 2
+	case len(asks) > 0:
+		return asks[0].Price
+	case len(bids) > 0:
+		return bids[0].Price
+	}
+	return 0
+}
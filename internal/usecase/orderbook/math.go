@@ -1,74 +1,75 @@
 package orderbook
 
 import (
-	"strconv"
+	"github.com/shopspring/decimal"
 
 	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
 )
 
-func BuyQtyFromAsks(asks []domain.Order, budget float64) (qty, avgPrice, spent float64) {
-	if budget <= 0 || len(asks) == 0 {
-		return 0, 0, 0
+// BuyQtyFromAsks — сколько монеты купить на budget USDT по askам. Суммы считаются
+// десятично: spent = Σ цена × объём точно, и spent никогда не больше budget.
+func BuyQtyFromAsks(asks []domain.Order, budget decimal.Decimal) (qty, avgPrice, spent decimal.Decimal) {
+	if !budget.IsPositive() || len(asks) == 0 {
+		return decimal.Zero, decimal.Zero, decimal.Zero
 	}
-	var grossSpent float64
 	for _, a := range asks {
-		p, err1 := strconv.ParseFloat(a.Price, 64)
-		q, err2 := strconv.ParseFloat(a.Quantity, 64)
-		if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
+		if a.Price <= 0 || a.Qty <= 0 {
 			continue
 		}
-		remain := budget - grossSpent
-		if remain <= 0 {
+		remain := budget.Sub(spent)
+		if !remain.IsPositive() {
 			break
 		}
-		costFull := p * q
-		if costFull <= remain {
-			grossSpent += costFull
-			qty += q
+		p, q := money.FromFloat(a.Price), money.FromFloat(a.Qty)
+		costFull := p.Mul(q)
+		if costFull.LessThanOrEqual(remain) {
+			spent = spent.Add(costFull)
+			qty = qty.Add(q)
 		} else {
-			takeQty := remain / p
-			if takeQty > 0 {
-				grossSpent += takeQty * p
-				qty += takeQty
+			// частичный уровень: доля монеты округляется вниз, чтобы не выйти за бюджет
+			takeQty, _ := remain.QuoRem(p, money.DivScale)
+			if takeQty.IsPositive() {
+				spent = spent.Add(takeQty.Mul(p))
+				qty = qty.Add(takeQty)
 			}
 			break
 		}
 	}
-	if qty <= 0 {
-		return 0, 0, 0
+	if !qty.IsPositive() {
+		return decimal.Zero, decimal.Zero, decimal.Zero
 	}
-	spent = grossSpent
-	avgPrice = spent / qty
+	avgPrice = money.Div(spent, qty)
 	return
 }
 
-func SellFromBids(bids []domain.Order, qty float64) (received, avgPrice float64) {
-	if qty <= 0 || len(bids) == 0 {
-		return 0, 0
+// SellFromBids — сколько USDT выручить за qty монеты по бидам.
+func SellFromBids(bids []domain.Order, qty decimal.Decimal) (received, avgPrice decimal.Decimal) {
+	if !qty.IsPositive() || len(bids) == 0 {
+		return decimal.Zero, decimal.Zero
 	}
-	var soldQty float64
+	var soldQty decimal.Decimal
 	for _, b := range bids {
-		p, err1 := strconv.ParseFloat(b.Price, 64)
-		q, err2 := strconv.ParseFloat(b.Quantity, 64)
-		if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
+		if b.Price <= 0 || b.Qty <= 0 {
 			continue
 		}
-		remain := qty - soldQty
-		if remain <= 0 {
+		remain := qty.Sub(soldQty)
+		if !remain.IsPositive() {
 			break
 		}
-		if q <= remain {
-			received += q * p
-			soldQty += q
+		p, q := money.FromFloat(b.Price), money.FromFloat(b.Qty)
+		if q.LessThanOrEqual(remain) {
+			received = received.Add(q.Mul(p))
+			soldQty = soldQty.Add(q)
 		} else {
-			received += remain * p
-			soldQty += remain
+			received = received.Add(remain.Mul(p))
+			soldQty = soldQty.Add(remain)
 			break
 		}
 	}
-	if soldQty <= 0 {
-		return 0, 0
+	if !soldQty.IsPositive() {
+		return decimal.Zero, decimal.Zero
 	}
-	avgPrice = received / soldQty
+	avgPrice = money.Div(received, soldQty)
 	return
 }
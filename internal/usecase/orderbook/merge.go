@@ -2,7 +2,6 @@ package orderbook
 
 import (
 	"sort"
-	"strconv"
 
 	"cryptobot/internal/domain"
 )
@@ -20,12 +19,10 @@ func CombinedAsks(books map[string]*domain.OrderBook) []Level {
 			continue
 		}
 		for _, a := range ob.Asks {
-			p, err1 := strconv.ParseFloat(a.Price, 64)
-			q, err2 := strconv.ParseFloat(a.Quantity, 64)
-			if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
+			if a.Price <= 0 || a.Qty <= 0 {
 				continue
 			}
-			out = append(out, Level{Exchange: ex, Price: p, Qty: q})
+			out = append(out, Level{Exchange: ex, Price: a.Price, Qty: a.Qty})
 		}
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].Price < out[j].Price })
@@ -39,12 +36,10 @@ func CombinedBids(books map[string]*domain.OrderBook) []Level {
 			continue
 		}
 		for _, b := range ob.Bids {
-			p, err1 := strconv.ParseFloat(b.Price, 64)
-			q, err2 := strconv.ParseFloat(b.Quantity, 64)
-			if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
+			if b.Price <= 0 || b.Qty <= 0 {
 				continue
 			}
-			out = append(out, Level{Exchange: ex, Price: p, Qty: q})
+			out = append(out, Level{Exchange: ex, Price: b.Price, Qty: b.Qty})
 		}
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].Price > out[j].Price })
@@ -0,0 +1,101 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cryptobot/internal/usecase/orderbook"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// defaultDepthBands — полосы глубины (±% от mid) по умолчанию.
+var defaultDepthBands = []float64{0.1, 0.5, 1, 2, 5}
+
+// BookRequest — вход для построения сводного стакана <Coin>/USDT.
+type BookRequest struct {
+	Coin  string
+	Tick  float64   // группировка по шагу цены (USDT)
+	Bps   float64   // группировка по шагу в б.п. (если Tick не задан)
+	Bands []float64 // полосы ±% для сводки глубины
+	Limit int       // максимум строк на сторону (0 — без ограничения)
+
+	Exchanges []string // ограничить набор бирж (пусто — все)
+}
+
+// BookResult — сводный стакан со всех бирж.
+type BookResult struct {
+	Coin        string                `json:"coin"`
+	BestAsk     float64               `json:"bestAsk"`
+	BestBid     float64               `json:"bestBid"`
+	Mid         float64               `json:"mid"`
+	Asks        []orderbook.LadderRow `json:"asks"`
+	Bids        []orderbook.LadderRow `json:"bids"`
+	Depth       []orderbook.DepthBand `json:"depth"`
+	Exchanges   []string              `json:"exchanges"`
+	Diagnostics []string              `json:"diagnostics"`
+	Issues      []BookIssue           `json:"issues,omitempty"`
+	BookAges    []BookAge             `json:"bookAges,omitempty"`
+	GeneratedAt string                `json:"generatedAt"`
+}
+
+// Book — сводная лестница <Coin>/USDT, по которой считает сценарий Optimal.
+func (s *Service) Book(ctx context.Context, in BookRequest) (BookResult, error) {
+	coin := strings.ToUpper(strings.TrimSpace(in.Coin))
+	if coin == "" {
+		return BookResult{}, fmt.Errorf("coin is required")
+	}
+	if isUSDT(coin) {
+		return BookResult{}, fmt.Errorf("стакан USDT/USDT не существует, выберите другую монету")
+	}
+
+	now := time.Now()
+	books, diags, issues, err := s.fetchBooks(ctx, coin, "USDT", in.Exchanges) // рыночные цены, без комиссий
+	if err != nil {
+		return BookResult{}, err
+	}
+	ages, staleDiags := bookAges(books, now, s.maxStale())
+	// сводный стакан строится по тем же стаканам, что пошли бы в сценарии
+	obs := scenario.Inputs{OrderBooks: toOrderBooks(books[coin], coin+"USDT", now), Now: now, MaxStale: s.maxStale()}.FreshBooks()
+	asks := orderbook.CombinedAsks(obs)
+	bids := orderbook.CombinedBids(obs)
+
+	res := BookResult{
+		Coin:        coin,
+		Mid:         orderbook.Mid(asks, bids),
+		Diagnostics: append(diags, staleDiags...),
+		Issues:      issues,
+		BookAges:    ages,
+		GeneratedAt: now.Format("15:04 02.01.2006"),
+	}
+	if len(asks) > 0 {
+		res.BestAsk = asks[0].Price
+	}
+	if len(bids) > 0 {
+		res.BestBid = bids[0].Price
+	}
+	for ex := range obs {
+		res.Exchanges = append(res.Exchanges, ex)
+	}
+	sort.Strings(res.Exchanges)
+
+	bands := in.Bands
+	if len(bands) == 0 {
+		bands = defaultDepthBands
+	}
+	res.Depth = orderbook.DepthBands(asks, bids, res.Mid, bands)
+
+	bk := orderbook.Bucketing{Tick: in.Tick, Bps: in.Bps}
+	res.Asks = limitRows(orderbook.BuildLadder(asks, true, bk), in.Limit)
+	res.Bids = limitRows(orderbook.BuildLadder(bids, false, bk), in.Limit)
+	return res, nil
+}
+
+func limitRows(rows []orderbook.LadderRow, n int) []orderbook.LadderRow {
+	if n > 0 && len(rows) > n {
+		return rows[:n]
+	}
+	return rows
+}
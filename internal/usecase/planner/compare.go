@@ -0,0 +1,152 @@
+package planner
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+)
+
+// CompareItem — результат одного сценария и его выгода относительно BestSingle.
+type CompareItem struct {
+	Result
+	Rank        int          `json:"rank"`       // 1 — лучший VWAP с учётом направления
+	SavingsAbs  money.Number `json:"savingsAbs"` // выгода в SavingsUnit (>0 — лучше BestSingle)
+	SavingsBps  float64      `json:"savingsBps"` // выгода по VWAP в б.п.
+	SavingsUnit string       `json:"savingsUnit"`
+	Error       string       `json:"error,omitempty"` // сценарий не смог построить план (например, не хватило глубины)
+}
+
+// CompareResult — все сценарии на одном наборе стаканов.
+type CompareResult struct {
+	Base        string        `json:"base"`
+	Quote       string        `json:"quote"`
+	Amount      float64       `json:"amount"`
+	Items       []CompareItem `json:"results"`
+	Diagnostics []string      `json:"diagnostics"`
+	Issues      []BookIssue   `json:"issues,omitempty"`
+	BookAges    []BookAge     `json:"bookAges,omitempty"`
+	GeneratedAt string        `json:"generatedAt"`
+
+	// Books — рыночные стаканы (без комиссий), по которым считались сценарии, по монетам:
+	// CLI показывает ровно их, а не тянет стаканы второй раз. В JSON не идут.
+	Books map[string][]Book `json:"-"`
+}
+
+// Compare тянет стаканы один раз и прогоняет по ним все сценарии.
+func (s *Service) Compare(ctx context.Context, in Request) (CompareResult, error) {
+	base, quote, err := s.normalizePair(in)
+	if err != nil {
+		return CompareResult{}, err
+	}
+
+	now := time.Now()
+	market, diags, issues, err := s.fetchBooks(ctx, base, quote, in.Exchanges)
+	if err != nil {
+		return CompareResult{}, err
+	}
+	books := s.withFees(market)
+
+	out := CompareResult{
+		Base:        base,
+		Quote:       quote,
+		Amount:      in.Amount,
+		Diagnostics: diags,
+		Issues:      issues,
+		GeneratedAt: now.Format("15:04 02.01.2006"),
+		Books:       market,
+	}
+	ages, staleDiags := bookAges(books, now, s.maxStale())
+	out.BookAges = ages
+	out.Diagnostics = append(out.Diagnostics, staleDiags...)
+	ref := bookRef(books, now)
+	for _, id := range Scenarios() {
+		strat, err := scenarioByID(id)
+		var res Result
+		if err == nil {
+			res, err = planWith(strat, id, base, quote, in.Amount, books, s.calc(now).with(in))
+		}
+		item := CompareItem{Result: res}
+		if err != nil {
+			item.Result = Result{Scenario: id, Base: base, Quote: quote, GeneratedAt: out.GeneratedAt}
+			item.Error = err.Error()
+		} else {
+			req := Request{Base: base, Quote: quote, Amount: in.Amount, Scenario: id, Exchanges: in.Exchanges}
+			if err := s.savePlan(ctx, req, &item.Result, ref, now); err != nil {
+				out.Diagnostics = append(out.Diagnostics, "history:err:"+err.Error())
+			}
+		}
+		out.Items = append(out.Items, item)
+	}
+
+	applySavings(out.Items, base, quote)
+	// лучшие сверху, сценарии без плана — в конце
+	sort.SliceStable(out.Items, func(i, j int) bool {
+		ri, rj := out.Items[i].Rank, out.Items[j].Rank
+		if ri == 0 || rj == 0 {
+			return rj == 0 && ri != 0
+		}
+		return ri < rj
+	})
+	return out, nil
+}
+
+// higherIsBetter — для покупки за USDT выгоднее меньший VWAP, в остальных маршрутах — больший.
+func higherIsBetter(base, quote string) bool {
+	return !(isUSDT(quote) && !isUSDT(base))
+}
+
+// applySavings считает выгоду каждого сценария против BestSingle и ранжирует по VWAP.
+func applySavings(items []CompareItem, base, quote string) {
+	higher := higherIsBetter(base, quote)
+	// Выгода считается в получаемой монете: USDT для покупки/продажи за USDT, BASE для маршрута.
+	unit := "USDT"
+	if !isUSDT(base) && !isUSDT(quote) {
+		unit = base
+	}
+
+	var ref *CompareItem
+	for i := range items {
+		if items[i].Scenario == "best_single" && items[i].VWAP.IsPositive() {
+			ref = &items[i]
+		}
+	}
+	for i := range items {
+		it := &items[i]
+		it.SavingsUnit = unit
+		if ref == nil || !it.VWAP.IsPositive() {
+			continue
+		}
+		diff := it.VWAP.Sub(ref.VWAP.Decimal)
+		if !higher {
+			diff = ref.VWAP.Sub(it.VWAP.Decimal)
+		}
+		it.SavingsBps = money.Div(diff, ref.VWAP.Decimal).InexactFloat64() * 10000
+		var savings decimal.Decimal
+		if higher {
+			savings = diff.Mul(it.TotalCost.Decimal) // за каждую потраченную единицу QUOTE
+		} else {
+			savings = diff.Mul(it.Generated.Decimal) // за каждую полученную единицу BASE
+		}
+		it.SavingsAbs = money.Num(money.Amount(savings, unit))
+	}
+
+	order := make([]*CompareItem, 0, len(items))
+	for i := range items {
+		if items[i].VWAP.IsPositive() {
+			order = append(order, &items[i])
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if higher {
+			return order[i].VWAP.GreaterThan(order[j].VWAP.Decimal)
+		}
+		return order[i].VWAP.LessThan(order[j].VWAP.Decimal)
+	})
+	for i, it := range order {
+		it.Rank = i + 1
+	}
+}
@@ -0,0 +1,77 @@
+package planner
+
+import "fmt"
+
+// Уверенность в плане: насколько цена плана опирается на видимую часть стаканов.
+const (
+	ConfidenceHigh   = "high"   // ни одна ножка не дошла до края видимого стакана
+	ConfidenceMedium = "medium" // ножка дошла до последнего видимого уровня или выбрала большую часть стороны
+	ConfidenceLow    = "low"    // видимая глубина исчерпана или заявка исполнена не полностью
+)
+
+// depthWarnPct — доля видимой стороны стакана, после которой уверенность снижается.
+const depthWarnPct = 80.0
+
+// markDepth проставляет ножкам расход видимой глубины стакана биржи: asks при покупке,
+// bids при продаже. Возвращает диагностику по ножкам, дошедшим до края стакана.
+// Учитываются только исполняемые ножки (Amount > 0): сценарии не кладут в Legs
+// биржи, которые не идут в исполнение.
+func markDepth(legs []Leg, books []Book, coin string, buy bool) []string {
+	byEx := make(map[string]Book, len(books))
+	for _, b := range books {
+		byEx[b.Exchange] = b
+	}
+	var diags []string
+	for i := range legs {
+		l := &legs[i]
+		side := byEx[l.Exchange].Bids
+		if buy {
+			side = byEx[l.Exchange].Asks
+		}
+		if len(side) == 0 || !l.Amount.IsPositive() {
+			continue
+		}
+		amount := l.Amount.InexactFloat64() // для долей глубины точности float64 достаточно
+		var total float64
+		for _, lv := range side {
+			total += lv.Qty
+		}
+		beforeLast := total - side[len(side)-1].Qty
+
+		const eps = 1e-9
+		l.DepthUsedPct = min(amount/total*100, 100)
+		l.Exhausted = amount >= total*(1-eps)
+		l.LastLevel = amount > beforeLast*(1+eps)
+		switch {
+		case l.Exhausted:
+			diags = append(diags, fmt.Sprintf("%s:depth-exhausted:%s all %d visible levels used, price beyond is unknown", l.Exchange, coin, len(side)))
+		case l.LastLevel:
+			diags = append(diags, fmt.Sprintf("%s:depth-last-level:%s reached level %d of %d", l.Exchange, coin, len(side), len(side)))
+		}
+	}
+	return diags
+}
+
+// confidence — итоговая уверенность по исполняемым ножкам плана (включая скрытые этапы маршрута).
+func confidence(legs []Leg, filled bool) string {
+	if !filled {
+		return ConfidenceLow
+	}
+	out, executed := ConfidenceHigh, 0
+	for _, l := range legs {
+		if !l.Amount.IsPositive() {
+			continue
+		}
+		executed++
+		switch {
+		case l.Exhausted:
+			return ConfidenceLow
+		case l.LastLevel || l.DepthUsedPct >= depthWarnPct:
+			out = ConfidenceMedium
+		}
+	}
+	if executed == 0 {
+		return ConfidenceLow
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package planner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+)
+
+func TestMarkDepthAndConfidence(t *testing.T) {
+	books := []Book{
+		{Exchange: "a", Asks: lv(100, 1, 101, 1, 102, 2)}, // видимо 4 монеты
+	}
+	tests := []struct {
+		name      string
+		amount    string
+		wantPct   float64
+		wantLast  bool
+		wantExh   bool
+		wantConf  string
+		wantDiags int
+	}{
+		{name: "shallow", amount: "1", wantPct: 25, wantConf: ConfidenceHigh},
+		{name: "deep", amount: "3.5", wantPct: 87.5, wantLast: true, wantConf: ConfidenceMedium, wantDiags: 1},
+		{name: "exhausted", amount: "4", wantPct: 100, wantLast: true, wantExh: true, wantConf: ConfidenceLow, wantDiags: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legs := []Leg{{Exchange: "a", Amount: money.Num(decimal.RequireFromString(tt.amount))}}
+			diags := markDepth(legs, books, "BTC", true)
+			l := legs[0]
+			if l.DepthUsedPct != tt.wantPct || l.LastLevel != tt.wantLast || l.Exhausted != tt.wantExh {
+				t.Errorf("leg = %+v", l)
+			}
+			if len(diags) != tt.wantDiags {
+				t.Errorf("diags = %v", diags)
+			}
+			if got := confidence(legs, true); got != tt.wantConf {
+				t.Errorf("confidence = %s, want %s", got, tt.wantConf)
+			}
+		})
+	}
+}
+
+func TestConfidenceIgnoresIdleLegs(t *testing.T) {
+	idle := Leg{Exchange: "b", Exhausted: true} // Amount = 0: в исполнение не идёт
+	if got := confidence([]Leg{{Exchange: "a", Amount: money.Num(decimal.NewFromInt(1))}, idle}, true); got != ConfidenceHigh {
+		t.Errorf("confidence = %s, want %s", got, ConfidenceHigh)
+	}
+	if got := confidence([]Leg{idle}, true); got != ConfidenceLow {
+		t.Errorf("confidence without executed legs = %s, want %s", got, ConfidenceLow)
+	}
+}
+
+// best_single исполняет одну биржу: мелкий стакан другой биржи не портит уверенность.
+func TestBestSingleDepthOnlyChosenVenue(t *testing.T) {
+	repo := fakeRepo{"BTC": {
+		{Exchange: "deep", Asks: lv(100, 100, 101, 100), Bids: lv(99, 100)},
+		{Exchange: "thin", Asks: lv(100.5, 1), Bids: lv(99, 100)},
+	}}
+	res, err := New(repo).Plan(context.Background(), Request{Base: "BTC", Quote: "USDT", Amount: 1000, Scenario: "best_single"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Legs) != 1 || res.Legs[0].Exchange != "deep" {
+		t.Fatalf("legs = %+v, want one leg on deep", res.Legs)
+	}
+	if res.Confidence != ConfidenceHigh {
+		t.Errorf("confidence = %s, want %s (diags %v)", res.Confidence, ConfidenceHigh, res.Diagnostics)
+	}
+	for _, d := range res.Diagnostics {
+		if strings.HasPrefix(d, "thin:depth-") {
+			t.Errorf("depth diagnostic for a venue that is not executed: %s", d)
+		}
+	}
+}
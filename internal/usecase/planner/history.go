@@ -0,0 +1,121 @@
+package planner
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrPlanNotFound — плана с таким ID нет в хранилище.
+var ErrPlanNotFound = errors.New("plan not found")
+
+// StoredPlan — сохранённый план: вход, результат и ссылка на снимок стаканов.
+type StoredPlan struct {
+	ID        string
+	CreatedAt time.Time
+	Request   Request
+	Result    Result
+	BookRef   string // отпечаток набора стаканов, по которому считали план
+}
+
+// PlanFilter — фильтры и пагинация для списка планов. Пустые поля не фильтруют.
+type PlanFilter struct {
+	Base     string
+	Quote    string
+	Scenario string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
+
+// PlanStore — хранилище истории планов (реализация в инфраструктуре).
+type PlanStore interface {
+	Save(ctx context.Context, p StoredPlan) error
+	Get(ctx context.Context, id string) (StoredPlan, error)
+	// List возвращает страницу планов (новые первыми) и общее число подходящих записей.
+	List(ctx context.Context, f PlanFilter) ([]StoredPlan, int, error)
+}
+
+// WithStore включает сохранение каждого рассчитанного плана.
+func (s *Service) WithStore(st PlanStore) *Service {
+	s.store = st
+	return s
+}
+
+// GetPlan — сохранённый план по ID.
+func (s *Service) GetPlan(ctx context.Context, id string) (StoredPlan, error) {
+	if s.store == nil {
+		return StoredPlan{}, ErrPlanNotFound
+	}
+	return s.store.Get(ctx, id)
+}
+
+// ListPlans — история планов с фильтрами.
+func (s *Service) ListPlans(ctx context.Context, f PlanFilter) ([]StoredPlan, int, error) {
+	if s.store == nil {
+		return nil, 0, nil
+	}
+	return s.store.List(ctx, f)
+}
+
+// savePlan сохраняет план и проставляет ему ID. Без хранилища — ничего не делает.
+func (s *Service) savePlan(ctx context.Context, in Request, res *Result, bookRef string, now time.Time) error {
+	if s.store == nil {
+		return nil
+	}
+	id, err := newPlanID()
+	if err != nil {
+		return err
+	}
+	res.ID = id
+	err = s.store.Save(ctx, StoredPlan{
+		ID:        id,
+		CreatedAt: now,
+		Request:   in,
+		Result:    *res,
+		BookRef:   bookRef,
+	})
+	if err != nil {
+		res.ID = ""
+		return fmt.Errorf("save plan: %w", err)
+	}
+	return nil
+}
+
+func newPlanID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// bookRef — короткий отпечаток набора стаканов: биржи, размеры сторон, лучшие цены и время.
+func bookRef(books map[string][]Book, now time.Time) string {
+	coins := make([]string, 0, len(books))
+	for c := range books {
+		coins = append(coins, c)
+	}
+	sort.Strings(coins)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d", now.UnixMilli())
+	for _, c := range coins {
+		for _, b := range books[c] {
+			_, _ = fmt.Fprintf(h, "|%s:%s:%d:%d", c, b.Exchange, len(b.Asks), len(b.Bids))
+			if len(b.Asks) > 0 {
+				_, _ = fmt.Fprintf(h, ":%g", b.Asks[0].Price)
+			}
+			if len(b.Bids) > 0 {
+				_, _ = fmt.Fprintf(h, ":%g", b.Bids[0].Price)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
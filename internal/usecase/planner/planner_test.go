@@ -0,0 +1,21 @@
+package planner
+
+import (
+	"context"
+	"time"
+)
+
+// fakeRepo — стаканы по монете; время стакана — момент запроса.
+type fakeRepo map[string][]Book
+
+func (r fakeRepo) FetchAllBooks(_ context.Context, coin string, _ int) ([]Book, []string, error) {
+	now := time.Now()
+	out := make([]Book, 0, len(r[coin]))
+	var diags []string
+	for _, b := range r[coin] {
+		b.Timestamp, b.ReceivedAt = now, now
+		out = append(out, b)
+		diags = append(diags, b.Exchange+":ok")
+	}
+	return out, diags, nil
+}
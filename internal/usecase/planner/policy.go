@@ -0,0 +1,101 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cryptobot/internal/usecase/scenario"
+)
+
+// Policy — настройки расчёта из конфигурации.
+type Policy struct {
+	DefaultScenario string             // сценарий, если в запросе не указан (пусто — optimal)
+	FeesBps         map[string]float64 // тейкер-комиссия биржи в б.п. (ключ — имя биржи в нижнем регистре)
+	MaxAmount       map[string]float64 // предельная сумма заявки по валюте оплаты (ключ — тикер)
+	Sanity          Sanity             // пороги проверки стаканов
+	MaxStale        time.Duration      // стаканы старше в расчёт не идут (0 — по умолчанию 10s, < 0 — без проверки)
+	Scenario        scenario.Params    // настройки сценариев
+}
+
+const defaultMaxStale = 10 * time.Second
+
+func (s *Service) maxStale() time.Duration {
+	switch {
+	case s.policy.MaxStale < 0:
+		return 0
+	case s.policy.MaxStale == 0:
+		return defaultMaxStale
+	}
+	return s.policy.MaxStale
+}
+
+// calc — настройки одного расчёта, общие для всех этапов плана.
+type calc struct {
+	now      time.Time
+	maxStale time.Duration
+	params   scenario.Params
+}
+
+func (s *Service) calc(now time.Time) calc {
+	return calc{now: now, maxStale: s.maxStale(), params: s.policy.Scenario}
+}
+
+// with — настройки расчёта с поправками из запроса.
+func (c calc) with(in Request) calc {
+	if in.SlippageBps > 0 {
+		c.params.SlippageBps = in.SlippageBps
+	}
+	return c
+}
+
+// WithPolicy задаёт сценарий по умолчанию, комиссии и лимиты.
+func (s *Service) WithPolicy(p Policy) *Service {
+	s.policy = p
+	return s
+}
+
+// DefaultScenario — сценарий, который используется, если в запросе он не указан.
+func (s *Service) DefaultScenario() string { return s.defaultScenario() }
+
+func (s *Service) defaultScenario() string {
+	if sc := strings.ToLower(strings.TrimSpace(s.policy.DefaultScenario)); sc != "" {
+		return sc
+	}
+	return "optimal"
+}
+
+// checkCaps проверяет сумму заявки по лимиту для валюты оплаты.
+func (s *Service) checkCaps(quote string, amount float64) error {
+	if max, ok := s.policy.MaxAmount[quote]; ok && max > 0 && amount > max {
+		return fmt.Errorf("сумма %g %s превышает лимит %g %s", amount, quote, max, quote)
+	}
+	return nil
+}
+
+// applyFees ухудшает цены стаканов на тейкер-комиссию биржи:
+// аски дороже на fee, биды дешевле — сценарии сразу считают цену с учётом комиссии.
+func (s *Service) applyFees(books []Book) []Book {
+	if len(s.policy.FeesBps) == 0 {
+		return books
+	}
+	out := make([]Book, 0, len(books))
+	for _, b := range books {
+		fee := s.policy.FeesBps[strings.ToLower(b.Exchange)] / 10000
+		if fee == 0 {
+			out = append(out, b)
+			continue
+		}
+		nb := b
+		nb.Asks = make([]Level, len(b.Asks))
+		for i, l := range b.Asks {
+			nb.Asks[i] = Level{Price: l.Price * (1 + fee), Qty: l.Qty}
+		}
+		nb.Bids = make([]Level, len(b.Bids))
+		for i, l := range b.Bids {
+			nb.Bids[i] = Level{Price: l.Price * (1 - fee), Qty: l.Qty}
+		}
+		out = append(out, nb)
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package planner
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+)
+
+// LegChange — изменение распределения на одной бирже между исходным планом и перерасчётом.
+type LegChange struct {
+	Exchange  string
+	OldAmount decimal.Decimal
+	NewAmount decimal.Decimal
+	OldPrice  decimal.Decimal
+	NewPrice  decimal.Decimal
+}
+
+// RequoteResult — перерасчёт сохранённого плана по текущим стаканам.
+type RequoteResult struct {
+	ID        string
+	CreatedAt time.Time
+	CheckedAt time.Time
+	Original  Result
+	Current   Result
+	VWAPDiff  decimal.Decimal // Current.VWAP - Original.VWAP
+	DriftPct  float64         // >0 — цена ухудшилась для клиента, <0 — улучшилась
+	Legs      []LegChange
+}
+
+// Requote пересчитывает сохранённый план тем же сценарием по свежим стаканам.
+// Перерасчёт не сохраняется в историю.
+func (s *Service) Requote(ctx context.Context, id string) (RequoteResult, error) {
+	p, err := s.GetPlan(ctx, id)
+	if err != nil {
+		return RequoteResult{}, err
+	}
+	books, _, _, err := s.fetchPairBooks(ctx, p.Request.Base, p.Request.Quote, p.Request.Exchanges)
+	if err != nil {
+		return RequoteResult{}, err
+	}
+	return requoteWith(p, books, s.calc(time.Now()))
+}
+
+func requoteWith(p StoredPlan, books map[string][]Book, c calc) (RequoteResult, error) {
+	now := c.now
+	sc := p.Result.Scenario
+	if sc == "" {
+		sc = p.Request.Scenario
+	}
+	strat, err := scenarioByID(sc)
+	if err != nil {
+		return RequoteResult{}, err
+	}
+	cur, err := planWith(strat, sc, p.Request.Base, p.Request.Quote, p.Request.Amount, books, c.with(p.Request))
+	if err != nil {
+		return RequoteResult{}, err
+	}
+	cur.ID = p.ID
+
+	out := RequoteResult{
+		ID:        p.ID,
+		CreatedAt: p.CreatedAt,
+		CheckedAt: now,
+		Original:  p.Result,
+		Current:   cur,
+		VWAPDiff:  cur.VWAP.Sub(p.Result.VWAP.Decimal),
+		Legs:      diffLegs(p.Result.Legs, cur.Legs),
+	}
+	if p.Result.VWAP.IsPositive() {
+		drift := money.Div(out.VWAPDiff, p.Result.VWAP.Decimal).InexactFloat64() * 100
+		if higherIsBetter(p.Request.Base, p.Request.Quote) {
+			drift = -drift
+		}
+		out.DriftPct = drift
+	}
+	return out, nil
+}
+
+// diffLegs сводит ножки по биржам и оставляет только изменившиеся.
+func diffLegs(old, cur []Leg) []LegChange {
+	byEx := map[string]*LegChange{}
+	get := func(ex string) *LegChange {
+		c := byEx[ex]
+		if c == nil {
+			c = &LegChange{Exchange: ex}
+			byEx[ex] = c
+		}
+		return c
+	}
+	for _, l := range old {
+		c := get(l.Exchange)
+		c.OldAmount = c.OldAmount.Add(l.Amount.Decimal)
+		c.OldPrice = l.Price.Decimal
+	}
+	for _, l := range cur {
+		c := get(l.Exchange)
+		c.NewAmount = c.NewAmount.Add(l.Amount.Decimal)
+		c.NewPrice = l.Price.Decimal
+	}
+
+	var out []LegChange
+	for _, c := range byEx {
+		if c.OldAmount.Equal(c.NewAmount) && c.OldPrice.Equal(c.NewPrice) {
+			continue
+		}
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Exchange < out[j].Exchange })
+	return out
+}
+
+// DriftMonitor — фоновая проверка свежих планов: помечает те, чей дрейф превысил порог,
+// пока план ещё в окне действия.
+type DriftMonitor struct {
+	svc       *Service
+	threshold float64       // порог дрейфа, %
+	window    time.Duration // сколько план считается действующим
+	interval  time.Duration
+
+	mu      sync.RWMutex
+	flagged []RequoteResult
+	lastRun time.Time
+}
+
+func NewDriftMonitor(svc *Service, thresholdPct float64, window, interval time.Duration) *DriftMonitor {
+	return &DriftMonitor{svc: svc, threshold: thresholdPct, window: window, interval: interval}
+}
+
+// Run крутит проверки до отмены ctx.
+func (m *DriftMonitor) Run(ctx context.Context) {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+	for {
+		m.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Flagged — планы с дрейфом выше порога по итогам последней проверки (худшие первыми).
+func (m *DriftMonitor) Flagged() ([]RequoteResult, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]RequoteResult(nil), m.flagged...), m.lastRun
+}
+
+func (m *DriftMonitor) check(ctx context.Context) {
+	now := time.Now()
+	plans, _, err := m.svc.ListPlans(ctx, PlanFilter{From: now.Add(-m.window)})
+	if err != nil {
+		return
+	}
+
+	// стаканы тянем один раз на пару, а не на каждый план
+	byPair := map[string][]StoredPlan{}
+	for _, p := range plans {
+		key := strings.ToUpper(p.Request.Base + "/" + p.Request.Quote + "/" + strings.Join(p.Request.Exchanges, ","))
+		byPair[key] = append(byPair[key], p)
+	}
+
+	var flagged []RequoteResult
+	for _, ps := range byPair {
+		if ctx.Err() != nil {
+			return
+		}
+		books, _, _, err := m.svc.fetchPairBooks(ctx, ps[0].Request.Base, ps[0].Request.Quote, ps[0].Request.Exchanges)
+		if err != nil {
+			continue
+		}
+		for _, p := range ps {
+			rq, err := requoteWith(p, books, m.svc.calc(now))
+			if err != nil || rq.DriftPct <= m.threshold {
+				continue
+			}
+			flagged = append(flagged, rq)
+		}
+	}
+	sort.Slice(flagged, func(i, j int) bool { return flagged[i].DriftPct > flagged[j].DriftPct })
+
+	m.mu.Lock()
+	m.flagged = flagged
+	m.lastRun = now
+	m.mu.Unlock()
+}
@@ -0,0 +1,173 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sanity — пороги проверки стаканов перед сценариями; нулевые значения — умолчания.
+type Sanity struct {
+	OutlierPct  float64 // отклонение mid биржи от медианы по биржам, % (по умолчанию 2)
+	MinSideUSDT float64 // сторона тоньше этого объёма в USDT помечается (по умолчанию 1000)
+}
+
+const (
+	defaultOutlierPct  = 2.0
+	defaultMinSideUSDT = 1000.0
+	minVenuesForMedian = 3 // на двух биржах не понять, какая из них врёт
+)
+
+// Проверки стаканов.
+const (
+	CheckNonMonotonic = "non-monotonic" // лестница не отсортирована — битые данные
+	CheckCrossed      = "crossed"       // лучший bid выше лучшего ask
+	CheckLocked       = "locked"        // лучший bid равен лучшему ask
+	CheckOutlier      = "outlier"       // mid далеко от медианы других бирж
+	CheckEmptyAsks    = "empty-asks"
+	CheckEmptyBids    = "empty-bids"
+	CheckThinAsks     = "thin-asks"
+	CheckThinBids     = "thin-bids"
+)
+
+// BookIssue — замечание к стакану биржи. Excluded — стакан убран из расчёта,
+// иначе он участвует, но помечен.
+type BookIssue struct {
+	Coin     string `json:"coin"`
+	Exchange string `json:"exchange"`
+	Check    string `json:"check"`
+	Excluded bool   `json:"excluded"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// String — та же запись строкой для Diagnostics: "<exchange>:<check>:<excluded|flagged>:<coin> <detail>".
+func (i BookIssue) String() string {
+	action := "flagged"
+	if i.Excluded {
+		action = "excluded"
+	}
+	s := fmt.Sprintf("%s:%s:%s:%s", i.Exchange, i.Check, action, i.Coin)
+	if i.Detail != "" {
+		s += " " + i.Detail
+	}
+	return s
+}
+
+// checkBooks проверяет стаканы одной монеты и возвращает те, что можно пускать в сценарии.
+func (s Sanity) checkBooks(coin string, books []Book) ([]Book, []BookIssue) {
+	outlierPct, minSide := s.OutlierPct, s.MinSideUSDT
+	if outlierPct <= 0 {
+		outlierPct = defaultOutlierPct
+	}
+	if minSide <= 0 {
+		minSide = defaultMinSideUSDT
+	}
+
+	var issues []BookIssue
+	issue := func(b Book, check string, excluded bool, format string, args ...any) {
+		issues = append(issues, BookIssue{
+			Coin: coin, Exchange: b.Exchange, Check: check, Excluded: excluded,
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	kept := make([]Book, 0, len(books))
+	for _, b := range books {
+		if i, ok := unsorted(b.Asks, true); ok {
+			issue(b, CheckNonMonotonic, true, "asks[%d]=%g < asks[%d]=%g", i, b.Asks[i].Price, i-1, b.Asks[i-1].Price)
+			continue
+		}
+		if i, ok := unsorted(b.Bids, false); ok {
+			issue(b, CheckNonMonotonic, true, "bids[%d]=%g > bids[%d]=%g", i, b.Bids[i].Price, i-1, b.Bids[i-1].Price)
+			continue
+		}
+		if len(b.Asks) > 0 && len(b.Bids) > 0 {
+			bid, ask := b.Bids[0].Price, b.Asks[0].Price
+			if bid > ask {
+				issue(b, CheckCrossed, true, "bid %g > ask %g", bid, ask)
+				continue
+			}
+			if bid == ask {
+				issue(b, CheckLocked, false, "bid = ask = %g", bid)
+			}
+		}
+		for _, side := range []struct {
+			levels      []Level
+			empty, thin string
+		}{
+			{b.Asks, CheckEmptyAsks, CheckThinAsks},
+			{b.Bids, CheckEmptyBids, CheckThinBids},
+		} {
+			if len(side.levels) == 0 {
+				issue(b, side.empty, false, "")
+			} else if n := notional(side.levels); n < minSide {
+				issue(b, side.thin, false, "%.2f USDT < %.2f", n, minSide)
+			}
+		}
+		kept = append(kept, b)
+	}
+
+	// выбросы по цене — только когда есть с кем сравнить
+	refs := make([]float64, 0, len(kept))
+	for _, b := range kept {
+		if p := refPrice(b); p > 0 {
+			refs = append(refs, p)
+		}
+	}
+	if len(refs) < minVenuesForMedian {
+		return kept, issues
+	}
+	med := median(refs)
+	out := kept[:0]
+	for _, b := range kept {
+		if p := refPrice(b); p > 0 {
+			if dev := (p - med) / med * 100; math.Abs(dev) > outlierPct {
+				issue(b, CheckOutlier, true, "mid %g vs median %g (%+.2f%%)", p, med, dev)
+				continue
+			}
+		}
+		out = append(out, b)
+	}
+	return out, issues
+}
+
+// unsorted — индекс первого уровня, нарушающего порядок (аски по возрастанию, биды по убыванию).
+func unsorted(levels []Level, asc bool) (int, bool) {
+	for i := 1; i < len(levels); i++ {
+		if (asc && levels[i].Price < levels[i-1].Price) || (!asc && levels[i].Price > levels[i-1].Price) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func notional(levels []Level) float64 {
+	var sum float64
+	for _, l := range levels {
+		sum += l.Price * l.Qty
+	}
+	return sum
+}
+
+// refPrice — mid стакана, а если одна сторона пуста — лучшая цена другой.
+func refPrice(b Book) float64 {
+	switch {
+	case len(b.Asks) > 0 && len(b.Bids) > 0:
+		return (b.Asks[0].Price + b.Bids[0].Price) / 2
+	case len(b.Asks) > 0:
+		return b.Asks[0].Price
+	case len(b.Bids) > 0:
+		return b.Bids[0].Price
+	}
+	return 0
+}
+
+func median(xs []float64) float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
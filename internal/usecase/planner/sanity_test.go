@@ -0,0 +1,121 @@
+package planner
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func lv(pq ...float64) []Level {
+	out := make([]Level, 0, len(pq)/2)
+	for i := 0; i+1 < len(pq); i += 2 {
+		out = append(out, Level{Price: pq[i], Qty: pq[i+1]})
+	}
+	return out
+}
+
+// book — стакан около 100 USDT с запасом глубины выше порога thin.
+func book(ex string, bid, ask float64) Book {
+	return Book{Exchange: ex, Asks: lv(ask, 50, ask+1, 50), Bids: lv(bid, 50, bid-1, 50)}
+}
+
+func TestSanityCheckBooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		books    []Book
+		wantKept []string
+		want     []string // BookIssue.String() без detail: "<ex>:<check>:<action>:<coin>"
+	}{
+		{
+			name:     "clean",
+			books:    []Book{book("a", 99, 100), book("b", 99, 100)},
+			wantKept: []string{"a", "b"},
+		},
+		{
+			name: "non-monotonic asks",
+			books: []Book{
+				{Exchange: "a", Asks: lv(100, 50, 99.5, 50), Bids: lv(99, 50)},
+				book("b", 99, 100),
+			},
+			wantKept: []string{"b"},
+			want:     []string{"a:non-monotonic:excluded:BTC"},
+		},
+		{
+			name: "non-monotonic bids",
+			books: []Book{
+				{Exchange: "a", Asks: lv(100, 50), Bids: lv(98, 50, 99, 50)},
+			},
+			want: []string{"a:non-monotonic:excluded:BTC"},
+		},
+		{
+			name:     "crossed",
+			books:    []Book{book("a", 101, 100), book("b", 99, 100)},
+			wantKept: []string{"b"},
+			want:     []string{"a:crossed:excluded:BTC"},
+		},
+		{
+			name:     "locked is flagged",
+			books:    []Book{book("a", 100, 100)},
+			wantKept: []string{"a"},
+			want:     []string{"a:locked:flagged:BTC"},
+		},
+		{
+			name: "empty and thin sides",
+			books: []Book{
+				{Exchange: "a", Asks: lv(100, 1), Bids: nil},
+			},
+			wantKept: []string{"a"},
+			want:     []string{"a:thin-asks:flagged:BTC", "a:empty-bids:flagged:BTC"},
+		},
+		{
+			name:     "outlier with three venues",
+			books:    []Book{book("a", 99, 100), book("b", 99.2, 100.2), book("c", 105, 106)},
+			wantKept: []string{"a", "b"},
+			want:     []string{"c:outlier:excluded:BTC"},
+		},
+		{
+			name:     "two venues are not enough for median",
+			books:    []Book{book("a", 99, 100), book("c", 105, 106)},
+			wantKept: []string{"a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, issues := Sanity{}.checkBooks("BTC", tt.books)
+			var names []string
+			for _, b := range kept {
+				names = append(names, b.Exchange)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantKept, ",") {
+				t.Errorf("kept = %v, want %v", names, tt.wantKept)
+			}
+			var got []string
+			for _, is := range issues {
+				got = append(got, strings.SplitN(is.String(), " ", 2)[0])
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Лестницу в исходном порядке бирж проверяет Sanity: Repo её не сортирует.
+func TestPlanExcludesUnsortedLadder(t *testing.T) {
+	repo := fakeRepo{"BTC": {
+		{Exchange: "a", Asks: lv(100, 50, 99, 50), Bids: lv(98, 50)},
+		book("b", 99, 100),
+	}}
+	res, err := New(repo).Plan(context.Background(), Request{Base: "BTC", Quote: "USDT", Amount: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Check != CheckNonMonotonic || !res.Issues[0].Excluded {
+		t.Fatalf("issues = %+v, want one excluded %s", res.Issues, CheckNonMonotonic)
+	}
+	for _, l := range res.Legs {
+		if l.Exchange == "a" {
+			t.Fatalf("excluded venue got a leg: %+v", l)
+		}
+	}
+}
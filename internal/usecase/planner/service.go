@@ -2,233 +2,381 @@ package planner
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
 	"cryptobot/internal/usecase/scenario"
 )
 
 // Service — чистый планировщик.
 type Service struct {
-	repo Repo
+	repo   Repo
+	store  PlanStore // необязательно: история планов
+	policy Policy
 }
 
 func New(repo Repo) *Service {
 	return &Service{repo: repo}
 }
 
+// Scenarios — идентификаторы сценариев в порядке показа; первый служит базой для сравнения.
+func Scenarios() []string { return scenario.IDs() }
+
+// ErrUnknownScenario — сценария с таким ID нет в реестре.
+var ErrUnknownScenario = errors.New("unknown scenario")
+
+// scenarioByID — сценарий из реестра; неизвестный ID — ошибка со списком известных.
+func scenarioByID(id string) (scenario.Strategy, error) {
+	if e, ok := scenario.Lookup(id); ok {
+		return e.Strategy, nil
+	}
+	return nil, fmt.Errorf("%w %q (supported: %s)", ErrUnknownScenario, id, strings.Join(scenario.IDs(), ", "))
+}
+
 // Plan — рассчитывает план исполнения:
 // 1) Quote=USDT,  Base!=USDT → покупка BASE за USDT (Buy)
 // 2) Base=USDT,   Quote!=USDT → продажа QUOTE за USDT (Sell)
 // 3) Base!=USDT,  Quote!=USDT → QUOTE->USDT и покупка BASE (через мост USDT)
 func (s *Service) Plan(ctx context.Context, in Request) (Result, error) {
-	base := strings.ToUpper(strings.TrimSpace(in.Base))
-	quote := strings.ToUpper(strings.TrimSpace(in.Quote))
+	base, quote, err := s.normalizePair(in)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// нормализуем название сценария
+	sc := strings.ToLower(strings.TrimSpace(in.Scenario))
+	if sc == "" {
+		sc = s.defaultScenario()
+	}
+
+	strat, err := scenarioByID(sc)
+	if err != nil {
+		return Result{}, err
+	}
+
+	now := time.Now()
+	books, diags, issues, err := s.fetchPairBooks(ctx, base, quote, in.Exchanges)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := planWith(strat, sc, base, quote, in.Amount, books, s.calc(now).with(in))
+	if err != nil {
+		return Result{}, err
+	}
+	ages, staleDiags := bookAges(books, now, s.maxStale())
+	res.Diagnostics = append(append(diags, staleDiags...), res.Diagnostics...)
+	res.Issues = issues
+	res.BookAges = ages
+
+	in.Base, in.Quote, in.Scenario = base, quote, sc
+	if err := s.savePlan(ctx, in, &res, bookRef(books, now), now); err != nil {
+		res.Diagnostics = append(res.Diagnostics, "history:err:"+err.Error())
+	}
+	return res, nil
+}
+
+func (s *Service) normalizePair(in Request) (base, quote string, err error) {
+	base = strings.ToUpper(strings.TrimSpace(in.Base))
+	quote = strings.ToUpper(strings.TrimSpace(in.Quote))
 	if base == "" || quote == "" {
-		return Result{}, fmt.Errorf("unsupported pair selection")
+		return "", "", fmt.Errorf("unsupported pair selection")
 	}
 	if base == quote {
-		return Result{}, fmt.Errorf("нельзя выбирать одинаковые монеты: %s/%s", base, quote)
+		return "", "", fmt.Errorf("нельзя выбирать одинаковые монеты: %s/%s", base, quote)
 	}
 	if in.Amount <= 0 {
-		return Result{}, fmt.Errorf("amount must be > 0")
+		return "", "", fmt.Errorf("amount must be > 0")
+	}
+	if err := s.checkCaps(quote, in.Amount); err != nil {
+		return "", "", err
 	}
+	return base, quote, nil
+}
 
-	// нормализуем название сценария
-	sc := strings.ToLower(strings.TrimSpace(in.Scenario))
-	if sc == "" {
-		sc = "optimal"
-	}
-	runScenario := func() interface {
-		Run(scenario.Inputs) scenario.Result
-		Name() string
-	} {
-		switch sc {
-		case "best_single":
-			return scenario.BestSingle{}
-		case "equal_split":
-			return scenario.EqualSplit{}
-		default:
-			return scenario.Optimal{}
-		}
-	}()
+// fetchPairBooks тянет стаканы <coin>/USDT для всех не-USDT монет пары (ключ — монета)
+// с ценами, ухудшенными на комиссии бирж. exchanges ограничивает набор бирж (пусто — все).
+func (s *Service) fetchPairBooks(ctx context.Context, base, quote string, exchanges []string) (map[string][]Book, []string, []BookIssue, error) {
+	books, diags, issues, err := s.fetchBooks(ctx, base, quote, exchanges)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return s.withFees(books), diags, issues, nil
+}
 
-	now := time.Now()
+// withFees — те же стаканы с комиссиями бирж в ценах; исходный набор не меняется.
+func (s *Service) withFees(books map[string][]Book) map[string][]Book {
+	out := make(map[string][]Book, len(books))
+	for coin, bs := range books {
+		out[coin] = s.applyFees(bs)
+	}
+	return out
+}
+
+// fetchBooks — то же без комиссий: рыночные стаканы как есть. Стаканы, не прошедшие
+// проверку (Sanity), в результат не попадают; замечания дублируются в diags.
+func (s *Service) fetchBooks(ctx context.Context, base, quote string, exchanges []string) (map[string][]Book, []string, []BookIssue, error) {
 	depth := 0 // «максимальная» глубина оставлена на реализацию Repo
+	out := map[string][]Book{}
+	var diags []string
+	var issues []BookIssue
+	for _, coin := range []string{quote, base} {
+		if isUSDT(coin) {
+			continue
+		}
+		books, d, err := s.repo.FetchAllBooks(ctx, coin, depth)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		books = filterBooks(books, exchanges)
+		if len(exchanges) > 0 && len(books) == 0 {
+			return nil, nil, nil, fmt.Errorf("нет стаканов %s/USDT на выбранных биржах: %s", coin, strings.Join(exchanges, ", "))
+		}
+		books, found := s.policy.Sanity.checkBooks(coin, books)
+		out[coin] = books
+		diags = append(diags, d...)
+		for _, is := range found {
+			diags = append(diags, is.String())
+		}
+		issues = append(issues, found...)
+	}
+	return out, diags, issues, nil
+}
 
+// filterBooks оставляет только стаканы указанных бирж (сравнение без учёта регистра).
+func filterBooks(books []Book, exchanges []string) []Book {
+	if len(exchanges) == 0 {
+		return books
+	}
+	allowed := make(map[string]bool, len(exchanges))
+	for _, ex := range exchanges {
+		allowed[strings.ToLower(strings.TrimSpace(ex))] = true
+	}
+	out := books[:0:0]
+	for _, b := range books {
+		if allowed[strings.ToLower(b.Exchange)] {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// planWith прогоняет сценарий по уже полученным стаканам (books[coin] — стаканы <coin>/USDT).
+// Суммы считаются десятично и округляются до точности актива только здесь, на выходе.
+func planWith(runScenario scenario.Strategy, sc, base, quote string, amount float64, books map[string][]Book, c calc) (Result, error) {
+	now := c.now
 	var res Result
+	var err error
 	res.Scenario = sc
 	res.Base = base
 	res.Quote = quote
 	res.GeneratedAt = now.Format("15:04 02.01.2006")
+	total := money.FromFloat(amount) // сумма запроса как её ввёл клиент
 
 	switch {
 	// === Покупка BASE за USDT ===
 	case !isUSDT(base) && isUSDT(quote):
-		// тянем стаканы <BASE>/USDT со всех бирж
-		books, diags, err := s.repo.FetchAllBooks(ctx, base, depth)
-		if err != nil {
-			return Result{}, err
-		}
-		res.Diagnostics = append(res.Diagnostics, diags...)
-
-		// готовим вход для сценария
+		// готовим вход для сценария по стаканам <BASE>/USDT
 		inp := scenario.Inputs{
 			Direction:  scenario.Buy,
 			Symbol:     base + "USDT",
-			Right:      base,      // для BUY это «получаемая» монета
-			Amount:     in.Amount, // бюджет в USDT
-			OrderBooks: toOrderBooks(books, base+"USDT", now),
+			Right:      base,  // для BUY это «получаемая» монета
+			Amount:     total, // бюджет в USDT
+			OrderBooks: toOrderBooks(books[base], base+"USDT", now),
 			Now:        now,
-			MaxStale:   0,
+			MaxStale:   c.maxStale,
+			Params:     c.params,
 		}
 		out := runScenario.Run(inp)
 
 		// маппинг результата
-		res.VWAP = round2(out.AveragePrice)   // USDT за 1 BASE
-		res.TotalCost = round2(out.TotalUSDT) // потрачено USDT
-		res.Unspent = round2(out.Leftover)    // не потратили USDT
-		res.Generated = out.TotalQty          // получили BASE
-		res.Legs = toPlanLegs(out.Legs)       // Qty — это BASE на ножке
-
-	// === Продажа QUOTE за USDT (покупаем USDT за монету) ===
-	case isUSDT(base) && !isUSDT(quote):
-		books, diags, err := s.repo.FetchAllBooks(ctx, quote, depth)
-		if err != nil {
+		res.VWAP = money.Num(money.Rate(out.AveragePrice))                                                // USDT за 1 BASE
+		res.Unspent = money.Num(money.Amount(decimal.Max(total.Sub(out.TotalUSDT), decimal.Zero), quote)) // не потратили USDT
+		res.TotalCost = money.Num(total.Sub(res.Unspent.Decimal))                                         // потрачено USDT
+		res.Generated = money.Num(money.Amount(out.TotalQty, base))                                       // получили BASE
+		// Qty — это BASE на ножке
+		if res.Legs, err = toPlanLegs(out.Legs, res.Generated.Decimal, base); err != nil {
 			return Result{}, err
 		}
-		res.Diagnostics = append(res.Diagnostics, diags...)
+		res.Venues = appendVenues(nil, base, out.Venues)
+		if !out.RiskAdjustedUSDT.IsZero() {
+			res.RiskAdjustedVWAP = money.Num(money.Rate(money.Div(out.RiskAdjustedUSDT, out.TotalQty)))
+		}
+		res.Diagnostics = markDepth(res.Legs, books[base], base, true)
+		res.Confidence = confidence(res.Legs, res.Unspent.IsZero())
 
+	// === Продажа QUOTE за USDT (покупаем USDT за монету) ===
+	case isUSDT(base) && !isUSDT(quote):
 		inp := scenario.Inputs{
 			Direction:  scenario.Sell,
 			Symbol:     quote + "USDT",
 			Right:      "USDT",
-			Amount:     in.Amount, // количество монеты QUOTE, которое продаём
-			OrderBooks: toOrderBooks(books, quote+"USDT", now),
+			Amount:     total, // количество монеты QUOTE, которое продаём
+			OrderBooks: toOrderBooks(books[quote], quote+"USDT", now),
 			Now:        now,
-			MaxStale:   0,
+			MaxStale:   c.maxStale,
+			Params:     c.params,
 		}
 		out := runScenario.Run(inp)
 
 		// Для SELL сценарии обычно не выставляют Leftover, поэтому считаем остаток сами
-		sold := out.TotalQty                // реально продали QUOTE (в валюте оплаты)
-		res.VWAP = round2(out.AveragePrice) // USDT за 1 QUOTE
+		res.VWAP = money.Num(money.Rate(out.AveragePrice)) // USDT за 1 QUOTE
 		// ВАЖНО: TotalCost должен быть в валюте оплаты (QUOTE), а не в USDT.
-		res.TotalCost = round2(sold)           // потратили QUOTE
-		res.Unspent = round2(in.Amount - sold) // не успели продать QUOTE
-		if res.Unspent < 0 {
-			res.Unspent = 0
+		res.Unspent = money.Num(money.Amount(decimal.Max(total.Sub(out.TotalQty), decimal.Zero), quote)) // не успели продать QUOTE
+		res.TotalCost = money.Num(total.Sub(res.Unspent.Decimal))                                        // потратили QUOTE
+		res.Generated = money.Num(money.Amount(out.TotalUSDT, base))                                     // получили USDT (база)
+		// ножки продажи QUOTE -> USDT
+		if res.Legs, err = toPlanLegs(out.Legs, res.TotalCost.Decimal, quote); err != nil {
+			return Result{}, err
 		}
-		res.Generated = out.TotalUSDT   // получили USDT (база)
-		res.Legs = toPlanLegs(out.Legs) // ножки продажи QUOTE -> USDT
+		res.Venues = appendVenues(nil, quote, out.Venues)
+		if !out.RiskAdjustedUSDT.IsZero() {
+			res.RiskAdjustedVWAP = money.Num(money.Rate(money.Div(out.RiskAdjustedUSDT, out.TotalQty)))
+		}
+		res.Diagnostics = markDepth(res.Legs, books[quote], quote, false)
+		res.Confidence = confidence(res.Legs, res.Unspent.IsZero())
 
 	// === Маршрут через USDT: QUOTE -> USDT -> BASE ===
 	case !isUSDT(base) && !isUSDT(quote):
 		// 1) продаём QUOTE -> USDT выбранным сценарием
-		booksQ, diagsQ, err := s.repo.FetchAllBooks(ctx, quote, depth)
-		if err != nil {
-			return Result{}, err
-		}
-		res.Diagnostics = append(res.Diagnostics, diagsQ...)
 		inSell := scenario.Inputs{
 			Direction:  scenario.Sell,
 			Symbol:     quote + "USDT",
 			Right:      "USDT",
-			Amount:     in.Amount, // QUOTE
-			OrderBooks: toOrderBooks(booksQ, quote+"USDT", now),
+			Amount:     total, // QUOTE
+			OrderBooks: toOrderBooks(books[quote], quote+"USDT", now),
 			Now:        now,
-			MaxStale:   0,
+			MaxStale:   c.maxStale,
+			Params:     c.params,
 		}
 		outSell := runScenario.Run(inSell)
 		soldQuote := outSell.TotalQty    // сколько QUOTE реально продали
 		usdProceeds := outSell.TotalUSDT // сколько USDT получили
 
-		if soldQuote <= 0 || usdProceeds <= 0 {
+		if !soldQuote.IsPositive() || !usdProceeds.IsPositive() {
 			return Result{}, fmt.Errorf("insufficient depth on QUOTE->USDT leg")
 		}
 
 		// 2) покупаем BASE на полученные USDT тем же сценарием
-		booksB, diagsB, err := s.repo.FetchAllBooks(ctx, base, depth)
-		if err != nil {
-			return Result{}, err
-		}
-		res.Diagnostics = append(res.Diagnostics, diagsB...)
 		inBuy := scenario.Inputs{
 			Direction:  scenario.Buy,
 			Symbol:     base + "USDT",
 			Right:      base,
 			Amount:     usdProceeds, // бюджет в USDT
-			OrderBooks: toOrderBooks(booksB, base+"USDT", now),
+			OrderBooks: toOrderBooks(books[base], base+"USDT", now),
 			Now:        now,
-			MaxStale:   0,
+			MaxStale:   c.maxStale,
+			Params:     c.params,
 		}
 		outBuy := runScenario.Run(inBuy)
 		gotBase := outBuy.TotalQty
-		if gotBase <= 0 {
+		if !gotBase.IsPositive() {
 			return Result{}, fmt.Errorf("insufficient depth on USDT->BASE leg")
 		}
 
 		// Итоги (для пары монета/монета показываем BASE за 1 QUOTE)
 		// Продаём QUOTE → получаем USDT; покупаем BASE за USDT.
 		// Эффективный кросс-курс BASE/QUOTE = (получено BASE) / (потрачено QUOTE).
-		res.VWAP = round2(gotBase / soldQuote)      // BASE/QUOTE
-		res.TotalCost = round2(soldQuote)           // потратили QUOTE
-		res.Unspent = round2(in.Amount - soldQuote) // остаток QUOTE
-		if res.Unspent < 0 {
-			res.Unspent = 0
-		}
-		res.Generated = gotBase
+		res.VWAP = money.Num(money.Rate(money.Div(gotBase, soldQuote)))                               // BASE/QUOTE
+		res.Unspent = money.Num(money.Amount(decimal.Max(total.Sub(soldQuote), decimal.Zero), quote)) // остаток QUOTE
+		res.TotalCost = money.Num(total.Sub(res.Unspent.Decimal))                                     // потратили QUOTE
+		res.Generated = money.Num(money.Amount(gotBase, base))
 
 		// В распределении показываем только покупку USDT->BASE (ножки продажи скрываем)
-		res.Legs = toPlanLegs(outBuy.Legs)
+		if res.Legs, err = toPlanLegs(outBuy.Legs, res.Generated.Decimal, base); err != nil {
+			return Result{}, err
+		}
+		res.Venues = appendVenues(appendVenues(nil, quote, outSell.Venues), base, outBuy.Venues)
+		if !outSell.RiskAdjustedUSDT.IsZero() && !outBuy.RiskAdjustedUSDT.IsZero() {
+			// поправка на риск: продажа даёт меньше USDT, покупка обходится дороже
+			sellAdj := money.Div(outSell.RiskAdjustedUSDT, usdProceeds)
+			buyAdj := money.Div(outBuy.RiskAdjustedUSDT, outBuy.TotalUSDT)
+			res.RiskAdjustedVWAP = money.Num(money.Rate(money.Div(money.Div(gotBase, soldQuote).Mul(sellAdj), buyAdj)))
+		}
+
+		// глубину проверяем на обоих этапах: скрытые ножки продажи тоже влияют на цену
+		sellLegs, err := toPlanLegs(outSell.Legs, res.TotalCost.Decimal, quote)
+		if err != nil {
+			return Result{}, err
+		}
+		res.Diagnostics = append(markDepth(sellLegs, books[quote], quote, false),
+			markDepth(res.Legs, books[base], base, true)...)
+		res.Confidence = confidence(append(sellLegs, res.Legs...), res.Unspent.IsZero() && money.Amount(usdProceeds.Sub(outBuy.TotalUSDT), "USDT").IsZero())
+		res.Stages = []Stage{
+			{Coin: quote, Side: "sell", Amount: res.TotalCost, USDT: money.Num(money.Amount(usdProceeds, "USDT")), VWAP: money.Num(money.Rate(outSell.AveragePrice)), Legs: sellLegs},
+			{Coin: base, Side: "buy", Amount: res.Generated, USDT: money.Num(money.Amount(outBuy.TotalUSDT, "USDT")), VWAP: money.Num(money.Rate(outBuy.AveragePrice)), Legs: res.Legs},
+		}
 	}
 
 	return res, nil
 }
 
-func round2(x float64) float64 { return math.Round(x*100) / 100 }
-
 // ------------------------ ВСПОМОГАТЕЛЬНЫЕ МАППЕРЫ ------------------------
 
 func toOrderBooks(src []Book, symbol string, now time.Time) map[string]*domain.OrderBook {
 	out := make(map[string]*domain.OrderBook, len(src))
 	for _, b := range src {
+		t, _ := bookTime(b, now)
 		ob := &domain.OrderBook{
 			Symbol:    symbol,
 			Exchange:  b.Exchange,
-			Timestamp: now.UnixMilli(),
-		}
-		ob.Asks = make([]domain.Order, 0, len(b.Asks))
-		for _, a := range b.Asks {
-			ob.Asks = append(ob.Asks, domain.Order{
-				Price:    strconv.FormatFloat(a.Price, 'f', 8, 64),
-				Quantity: strconv.FormatFloat(a.Qty, 'f', 8, 64),
-			})
-		}
-		ob.Bids = make([]domain.Order, 0, len(b.Bids))
-		for _, d := range b.Bids {
-			ob.Bids = append(ob.Bids, domain.Order{
-				Price:    strconv.FormatFloat(d.Price, 'f', 8, 64),
-				Quantity: strconv.FormatFloat(d.Qty, 'f', 8, 64),
-			})
+			Timestamp: t.UnixMilli(),
+			Asks:      b.Asks, // сценарии стаканы не меняют — срезы общие
+			Bids:      b.Bids,
 		}
 		out[b.Exchange] = ob
 	}
 	return out
 }
 
-func toPlanLegs(src []scenario.Leg) []Leg {
+// appendVenues добавляет выбор набора бирж этапа плана по монете coin (если сценарий его делал).
+func appendVenues(dst []VenueSelection, coin string, v *scenario.VenueSelection) []VenueSelection {
+	if v == nil {
+		return dst
+	}
+	return append(dst, VenueSelection{
+		Coin:         coin,
+		Exchanges:    v.Exchanges,
+		ToleranceBps: v.ToleranceBps,
+		OptimalVWAP:  money.Num(money.Rate(v.OptimalVWAP)),
+		CostDiffUSDT: money.Num(money.Amount(v.CostDiffUSDT, "USDT")),
+		CostDiffBps:  v.CostDiffBps,
+	})
+}
+
+// toPlanLegs переводит ножки сценария в ножки плана: Amount округляется до точности
+// asset, а остаток округления уходит в самую крупную ножку, чтобы сумма ножек
+// совпала с итогом total. Ножки — разбиение итога, поэтому остаток не больше
+// единицы точности на ножку; большее расхождение — не округление, а ножки,
+// которые не складываются в итог, и такой план не отдаётся.
+func toPlanLegs(src []scenario.Leg, total decimal.Decimal, asset string) ([]Leg, error) {
 	legs := make([]Leg, 0, len(src))
-	for _, l := range src {
+	sum, largest := decimal.Zero, -1
+	for i, l := range src {
+		amt := money.Amount(l.Qty, asset)
 		legs = append(legs, Leg{
 			Exchange: l.Exchange,
-			Amount:   l.Qty,   // Qty монеты на ножке (при base=USDT фронт пересчитает в USDT)
-			Price:    l.Price, // цена (USDT/монета)
+			Amount:   money.Num(amt),                 // Qty монеты на ножке (при base=USDT фронт пересчитает в USDT)
+			Price:    money.Num(money.Rate(l.Price)), // цена (USDT/монета)
 		})
+		sum = sum.Add(amt)
+		if largest < 0 || amt.GreaterThan(legs[largest].Amount.Decimal) {
+			largest = i
+		}
+	}
+	residual := total.Sub(sum)
+	if residual.IsZero() {
+		return legs, nil
+	}
+	limit := money.Unit(asset).Mul(decimal.NewFromInt(int64(len(legs))))
+	if largest < 0 || residual.Abs().GreaterThan(limit) {
+		return nil, fmt.Errorf("ножки плана (%s %s) не сходятся с итогом %s %s", sum, asset, total, asset)
 	}
-	return legs
+	legs[largest].Amount = money.Num(legs[largest].Amount.Add(residual))
+	return legs, nil
 }
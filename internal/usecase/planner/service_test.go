@@ -0,0 +1,185 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// testRepo — BTC и ETH на четырёх биржах с разной глубиной; "thin" даёт частичные
+// исполнения и ножки, которые упираются в край стакана.
+var testRepo = fakeRepo{
+	"BTC": {
+		{Exchange: "a", Asks: lv(100000.3, 0.013, 100101, 0.5, 100250.75, 2), Bids: lv(99990, 0.5, 99950.5, 1.5)},
+		{Exchange: "b", Asks: lv(100000.5, 0.02, 100200, 5), Bids: lv(99995.5, 0.2, 99900, 3)},
+		{Exchange: "c", Asks: lv(100050.1, 0.7, 100120, 1.1), Bids: lv(99980.7, 0.9, 99970, 2)},
+		{Exchange: "thin", Asks: lv(100010, 0.004), Bids: lv(99992, 0.003)},
+	},
+	"ETH": {
+		{Exchange: "a", Asks: lv(3000.13, 1.3, 3001.7, 5), Bids: lv(2999.3, 3.7, 2998, 10)},
+		{Exchange: "b", Asks: lv(3000.5, 2.1, 3002, 8), Bids: lv(2999.9, 1.9, 2997.4, 6)},
+		{Exchange: "c", Asks: lv(3000.2, 0.8, 3003.3, 4), Bids: lv(2999.5, 2.2, 2996, 7)},
+	},
+}
+
+func sumLegs(legs []Leg) decimal.Decimal {
+	var sum decimal.Decimal
+	for _, l := range legs {
+		sum = sum.Add(l.Amount.Decimal)
+	}
+	return sum
+}
+
+// Каждый сценарий на каждом направлении: ножки неотрицательны и складываются
+// в итог, а TotalCost + Unspent — ровно сумма запроса.
+func TestPlanReconciles(t *testing.T) {
+	requests := []Request{
+		{Base: "BTC", Quote: "USDT", Amount: 1234.57},
+		{Base: "BTC", Quote: "USDT", Amount: 987654.32}, // больше видимой глубины
+		{Base: "USDT", Quote: "BTC", Amount: 0.123},
+		{Base: "USDT", Quote: "BTC", Amount: 25}, // больше видимой глубины
+		{Base: "BTC", Quote: "ETH", Amount: 1.7},
+		{Base: "ETH", Quote: "BTC", Amount: 0.05},
+	}
+	svc := New(testRepo)
+	for _, req := range requests {
+		for _, sc := range Scenarios() {
+			req.Scenario = sc
+			t.Run(fmt.Sprintf("%s-%s-%v/%s", req.Quote, req.Base, req.Amount, sc), func(t *testing.T) {
+				res, err := svc.Plan(context.Background(), req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(res.Legs) == 0 {
+					t.Fatal("no legs")
+				}
+				for _, l := range res.Legs {
+					if l.Amount.IsNegative() {
+						t.Errorf("negative leg %s: %s", l.Exchange, l.Amount)
+					}
+				}
+				if got, want := res.TotalCost.Add(res.Unspent.Decimal), money.FromFloat(req.Amount); !got.Equal(want) {
+					t.Errorf("TotalCost + Unspent = %s, want %s", got, want)
+				}
+				legsTotal := res.Generated // покупка и маршрут: ножки в BASE
+				if isUSDT(res.Base) {
+					legsTotal = res.TotalCost // продажа: ножки в QUOTE
+				}
+				if got := sumLegs(res.Legs); !got.Equal(legsTotal.Decimal) {
+					t.Errorf("sum of legs = %s, want %s", got, legsTotal)
+				}
+				if !isUSDT(res.Base) && !isUSDT(res.Quote) {
+					if len(res.Stages) != 2 {
+						t.Fatalf("stages = %+v", res.Stages)
+					}
+					if got := sumLegs(res.Stages[0].Legs); !got.Equal(res.TotalCost.Decimal) {
+						t.Errorf("sum of sell stage legs = %s, want %s", got, res.TotalCost)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestToPlanLegs(t *testing.T) {
+	leg := func(ex, qty string) scenario.Leg {
+		return scenario.Leg{Exchange: ex, Qty: decimal.RequireFromString(qty), Price: decimal.NewFromInt(100)}
+	}
+	tests := []struct {
+		name    string
+		src     []scenario.Leg
+		total   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "exact",
+			src:   []scenario.Leg{leg("a", "0.5"), leg("b", "0.25")},
+			total: "0.75",
+			want:  []string{"0.5", "0.25"},
+		},
+		{
+			name:  "rounding remainder goes to the largest leg",
+			src:   []scenario.Leg{leg("a", "0.123456784"), leg("b", "0.000000014"), leg("c", "0.000000014")},
+			total: "0.12345681",
+			want:  []string{"0.12345679", "0.00000001", "0.00000001"},
+		},
+		{
+			// варианты, а не разбиение: в сумме втрое больше итога
+			name:    "alternatives are rejected",
+			src:     []scenario.Leg{leg("a", "4.975"), leg("b", "4.95"), leg("c", "4.92561943")},
+			total:   "4.975",
+			wantErr: true,
+		},
+		{
+			name:    "legs short of total",
+			src:     []scenario.Leg{leg("a", "1")},
+			total:   "2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legs, err := toPlanLegs(tt.src, decimal.RequireFromString(tt.total), "BTC")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("legs = %+v, want error", legs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, w := range tt.want {
+				if !legs[i].Amount.Equal(decimal.RequireFromString(w)) {
+					t.Errorf("leg %d = %s, want %s", i, legs[i].Amount, w)
+				}
+			}
+		})
+	}
+}
+
+// Compare отдаёт стаканы, по которым считал, — рыночные, без комиссий:
+// CLI показывает их вместо второго запроса к биржам.
+func TestCompareReturnsMarketBooks(t *testing.T) {
+	svc := New(testRepo).WithPolicy(Policy{FeesBps: map[string]float64{"a": 10}})
+	res, err := svc.Compare(context.Background(), Request{Base: "BTC", Quote: "ETH", Amount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, coin := range []string{"BTC", "ETH"} {
+		if len(res.Books[coin]) != len(testRepo[coin]) {
+			t.Fatalf("%s books = %d, want %d", coin, len(res.Books[coin]), len(testRepo[coin]))
+		}
+		for i, b := range res.Books[coin] {
+			if want := testRepo[coin][i]; b.Exchange != want.Exchange || b.Asks[0] != want.Asks[0] || b.Bids[0] != want.Bids[0] {
+				t.Errorf("%s %s: top = %v/%v, want market %v/%v", coin, b.Exchange, b.Asks[0], b.Bids[0], want.Asks[0], want.Bids[0])
+			}
+		}
+	}
+}
+
+// Неизвестный сценарий — ошибка до похода на биржи, а не молчаливый optimal.
+func TestPlanUnknownScenario(t *testing.T) {
+	svc := New(testRepo)
+	_, err := svc.Plan(context.Background(), Request{Base: "BTC", Quote: "USDT", Amount: 100, Scenario: "optimall"})
+	if !errors.Is(err, ErrUnknownScenario) {
+		t.Fatalf("err = %v, want ErrUnknownScenario", err)
+	}
+	for _, id := range Scenarios() {
+		if !strings.Contains(err.Error(), id) {
+			t.Errorf("err = %q does not list %q", err, id)
+		}
+	}
+	res, err := svc.Plan(context.Background(), Request{Base: "BTC", Quote: "USDT", Amount: 100, Scenario: " Best_Single "})
+	if err != nil || res.Scenario != "best_single" {
+		t.Errorf("scenario = %q, err = %v", res.Scenario, err)
+	}
+}
@@ -0,0 +1,58 @@
+package planner
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BookAge — возраст стакана биржи на момент расчёта.
+type BookAge struct {
+	Coin     string `json:"coin"`
+	Exchange string `json:"exchange"`
+	AgeMs    int64  `json:"ageMs"`
+	Source   string `json:"source"` // exchange — по времени биржи, received — по времени получения
+	Stale    bool   `json:"stale"`  // старше MaxStale: в расчёт не пошёл
+}
+
+// bookTime — время стакана: по бирже, если она его сообщает, иначе время получения
+// (а если нет и его — now, как у стакана «только что»).
+func bookTime(b Book, now time.Time) (time.Time, string) {
+	switch {
+	case !b.Timestamp.IsZero():
+		return b.Timestamp, "exchange"
+	case !b.ReceivedAt.IsZero():
+		return b.ReceivedAt, "received"
+	}
+	return now, "received"
+}
+
+// bookAges — возраст всех стаканов и диагностика по устаревшим (их пропускают сценарии).
+func bookAges(books map[string][]Book, now time.Time, maxStale time.Duration) ([]BookAge, []string) {
+	coins := make([]string, 0, len(books))
+	for coin := range books {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+
+	var ages []BookAge
+	var diags []string
+	for _, coin := range coins {
+		for _, b := range books[coin] {
+			t, src := bookTime(b, now)
+			age := max(now.Sub(t), 0) // часы биржи могут спешить
+			a := BookAge{
+				Coin:     coin,
+				Exchange: b.Exchange,
+				AgeMs:    age.Milliseconds(),
+				Source:   src,
+				Stale:    maxStale > 0 && age > maxStale,
+			}
+			if a.Stale {
+				diags = append(diags, fmt.Sprintf("%s:stale:excluded:%s age=%s > %s", b.Exchange, coin, age.Round(time.Millisecond), maxStale))
+			}
+			ages = append(ages, a)
+		}
+	}
+	return ages, diags
+}
@@ -0,0 +1,35 @@
+package planner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBookAges(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	books := map[string][]Book{"BTC": {
+		{Exchange: "fresh", Timestamp: now.Add(-2 * time.Second), ReceivedAt: now},
+		{Exchange: "old", Timestamp: now.Add(-time.Minute), ReceivedAt: now},
+		{Exchange: "nots", ReceivedAt: now.Add(-3 * time.Second)},
+		{Exchange: "ahead", Timestamp: now.Add(time.Second), ReceivedAt: now},
+	}}
+	ages, diags := bookAges(books, now, 10*time.Second)
+
+	want := []BookAge{
+		{Coin: "BTC", Exchange: "fresh", AgeMs: 2000, Source: "exchange"},
+		{Coin: "BTC", Exchange: "old", AgeMs: 60000, Source: "exchange", Stale: true},
+		{Coin: "BTC", Exchange: "nots", AgeMs: 3000, Source: "received"},
+		{Coin: "BTC", Exchange: "ahead", AgeMs: 0, Source: "exchange"},
+	}
+	if len(ages) != len(want) {
+		t.Fatalf("ages = %+v", ages)
+	}
+	for i := range want {
+		if ages[i] != want[i] {
+			t.Errorf("ages[%d] = %+v, want %+v", i, ages[i], want[i])
+		}
+	}
+	if len(diags) != 1 || diags[0] != "old:stale:excluded:BTC age=1m0s > 10s" {
+		t.Errorf("diags = %q", diags)
+	}
+}
@@ -4,26 +4,54 @@ import (
 	"context"
 	"strings"
 	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
 )
 
 // ====== Чистые типы use-case (не зависят от HTTP и конкретных бирж) ======
 
-type Level struct {
-	Price float64 // цена
-	Qty   float64 // количество
-}
+// Level — уровень стакана; тот же тип, что у сценариев, поэтому стаканы
+// передаются в сценарии без копирования.
+type Level = domain.Order
 
 type Book struct {
-	Exchange string
-	Asks     []Level
-	Bids     []Level
+	Exchange   string
+	Asks       []Level
+	Bids       []Level
+	Timestamp  time.Time // время стакана по бирже (нулевое — биржа не сообщает)
+	ReceivedAt time.Time // когда ответ биржи получен локально
 }
 
 // Leg — одна "ножка" плана на конкретной бирже.
 type Leg struct {
-	Exchange string
-	Amount   float64 // количество монеты (BASE при покупке, QUOTE при продаже)
-	Price    float64 // цена (USDT за 1 BASE или USDT за 1 QUOTE)
+	Exchange string       `json:"exchange"`
+	Amount   money.Number `json:"amount"` // количество монеты (BASE при покупке, QUOTE при продаже)
+	Price    money.Number `json:"price"`  // цена (USDT за 1 BASE или USDT за 1 QUOTE)
+
+	DepthUsedPct float64 `json:"depthUsedPct"`        // доля видимой стороны стакана, выбранная ножкой, %
+	LastLevel    bool    `json:"lastLevel,omitempty"` // ножка дошла до последнего видимого уровня
+	Exhausted    bool    `json:"exhausted,omitempty"` // видимая глубина выбрана целиком — дальше цена неизвестна
+}
+
+// VenueSelection — набор бирж, который выбрал сценарий min_venues на этапе плана по монете Coin.
+type VenueSelection struct {
+	Coin         string       `json:"coin"`
+	Exchanges    []string     `json:"exchanges"`
+	ToleranceBps float64      `json:"toleranceBps"`
+	OptimalVWAP  money.Number `json:"optimalVwap"`  // VWAP optimal по всем биржам
+	CostDiffUSDT money.Number `json:"costDiffUsdt"` // во сколько обошёлся отказ от остальных бирж
+	CostDiffBps  float64      `json:"costDiffBps"`
+}
+
+// Stage — один этап маршрута монета → USDT → монета.
+type Stage struct {
+	Coin   string       `json:"coin"`   // QUOTE на этапе продажи, BASE на этапе покупки
+	Side   string       `json:"side"`   // sell | buy
+	Amount money.Number `json:"amount"` // сколько монеты продано / куплено
+	USDT   money.Number `json:"usdt"`   // сколько USDT получено / потрачено
+	VWAP   money.Number `json:"vwap"`   // USDT за 1 монету
+	Legs   []Leg        `json:"legs"`
 }
 
 // Request — вход для расчёта плана.
@@ -31,21 +59,37 @@ type Request struct {
 	Base     string  // что покупаем (или что получаем в итоге для sideRoute)
 	Quote    string  // чем платим (или что тратим для sideRoute)
 	Amount   float64 // сколько платим (в USDT для sideBuy; в монете для sideSell/sideRoute)
-	Scenario string  // пока игнорируется (будем внедрять позже)
+	Scenario string  // ID сценария из реестра scenario (по умолчанию optimal)
+
+	Exchanges []string // ограничить набор бирж (пусто — все)
+
+	SlippageBps float64 // min_venues: допуск к VWAP optimal, б.п. (0 — из конфига)
 }
 
-// Result — результат расчёта.
+// Result — результат расчёта. Суммы округлены до точности своего актива
+// (money.Precision), курсы — до значащих цифр (money.Rate); TotalCost + Unspent
+// равно сумме запроса, а сумма Amount ножек — Generated (или TotalCost при продаже).
 type Result struct {
-	Scenario    string
-	Base        string
-	Quote       string
-	VWAP        float64 // см. ниже: единицы зависят от направления
-	TotalCost   float64 // сколько реально потратили (в USDT для sideBuy, в QUOTE для sideRoute)
-	Unspent     float64 // остаток неиспользованных средств (в тех же единицах, что и TotalCost)
-	Generated   float64 // сколько реально получили целевой монеты (BASE для sideBuy/sideRoute; USDT для sideSell)
-	Legs        []Leg
-	Diagnostics []string
-	GeneratedAt string // "15:04 02.01.2006"
+	ID          string           `json:"id,omitempty"` // ID в истории планов (пусто, если история не подключена)
+	Scenario    string           `json:"scenario"`
+	Base        string           `json:"base"`
+	Quote       string           `json:"quote"`
+	VWAP        money.Number     `json:"vwap"`      // см. ниже: единицы зависят от направления
+	TotalCost   money.Number     `json:"totalCost"` // сколько реально потратили (в USDT для sideBuy, в QUOTE для sideRoute)
+	Unspent     money.Number     `json:"unspent"`   // остаток неиспользованных средств (в тех же единицах, что и TotalCost)
+	Generated   money.Number     `json:"generated"` // сколько реально получили целевой монеты (BASE для sideBuy/sideRoute; USDT для sideSell)
+	Legs        []Leg            `json:"legs"`
+	Diagnostics []string         `json:"diagnostics"`
+	Issues      []BookIssue      `json:"issues,omitempty"` // замечания проверки стаканов
+	BookAges    []BookAge        `json:"bookAges,omitempty"`
+	Confidence  string           `json:"confidence"`       // high | medium | low, см. ConfidenceHigh и далее
+	Venues      []VenueSelection `json:"venues,omitempty"` // min_venues: выбранные биржи по этапам плана
+	Stages      []Stage          `json:"stages,omitempty"` // маршрут монета → монета: продажа QUOTE и покупка BASE
+	GeneratedAt string           `json:"generatedAt"`      // "15:04 02.01.2006"
+
+	// RiskAdjustedVWAP — VWAP с поправкой на штрафы за риск бирж (Policy.Scenario.RiskBps):
+	// во что план обходится с учётом риска. 0 — штрафы не заданы.
+	RiskAdjustedVWAP money.Number `json:"riskAdjustedVwap,omitzero"`
 }
 
 // Repo — интерфейс доступа к стаканам (реализация будет в инфраструктуре).
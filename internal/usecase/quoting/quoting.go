@@ -0,0 +1,201 @@
+package quoting
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
+)
+
+// Markup — наценка деска. Bps — в б.п. от рыночной цены, Fixed — абсолютная
+// поправка цены в её же единицах (USDT за 1 BASE, USDT за 1 QUOTE или BASE/QUOTE).
+type Markup struct {
+	Bps   float64 `json:"bps"`
+	Fixed float64 `json:"fixed"`
+}
+
+// Config — настройки котирования.
+type Config struct {
+	Secret      []byte            // ключ HMAC для подписи котировок
+	Validity    time.Duration     // срок действия котировки
+	Tiers       map[string]Markup // наценка по уровню клиента
+	DefaultTier string
+}
+
+// Planner — то, что нужно котировщику от планировщика.
+type Planner interface {
+	Plan(ctx context.Context, in planner.Request) (planner.Result, error)
+}
+
+// Request — запрос котировки клиенту.
+type Request struct {
+	planner.Request
+	Tier string
+}
+
+// Quote — подписанная клиентская котировка.
+type Quote struct {
+	PlanID      string
+	Tier        string
+	Base        string
+	Quote       string
+	Amount      float64 // сколько клиент отдаёт (в Quote)
+	Scenario    string
+	MarketPrice decimal.Decimal // VWAP плана без наценки (только для деска, в подпись не входит)
+	ClientPrice decimal.Decimal // цена для клиента в тех же единицах
+	Receive     decimal.Decimal // сколько клиент получит по ClientPrice
+	ReceiveUnit string
+	DeskMargin  decimal.Decimal // разница между рыночным и клиентским объёмом (в ReceiveUnit, только для деска)
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	Signature   string
+}
+
+// Verification — результат проверки котировки.
+type Verification struct {
+	Valid     bool // подпись верна и срок не истёк — котировку можно исполнять
+	Signed    bool // подпись верна
+	Expired   bool
+	ExpiresIn time.Duration
+	Reason    string
+}
+
+var ErrUnknownTier = errors.New("unknown client tier")
+
+// Service — слой клиентских котировок поверх planner.Service.
+type Service struct {
+	planner Planner
+	cfg     Config
+	now     func() time.Time
+}
+
+func New(p Planner, cfg Config) (*Service, error) {
+	if len(cfg.Secret) == 0 {
+		return nil, fmt.Errorf("quoting: empty signing secret")
+	}
+	if cfg.Validity <= 0 {
+		cfg.Validity = time.Minute
+	}
+	cfg.DefaultTier = strings.ToLower(strings.TrimSpace(cfg.DefaultTier))
+	if cfg.DefaultTier == "" {
+		cfg.DefaultTier = "default"
+	}
+	// уровни сравниваем без учёта регистра
+	tiers := make(map[string]Markup, len(cfg.Tiers)+1)
+	for name, m := range cfg.Tiers {
+		if m.Bps < 0 || m.Fixed < 0 {
+			return nil, fmt.Errorf("quoting: tier %q: markup must be >= 0", name)
+		}
+		tiers[strings.ToLower(strings.TrimSpace(name))] = m
+	}
+	if _, ok := tiers[cfg.DefaultTier]; !ok {
+		tiers[cfg.DefaultTier] = Markup{}
+	}
+	cfg.Tiers = tiers
+	return &Service{planner: p, cfg: cfg, now: time.Now}, nil
+}
+
+// Quote строит план и выдаёт клиенту подписанную цену с наценкой его уровня.
+func (s *Service) Quote(ctx context.Context, in Request) (Quote, error) {
+	tier := strings.ToLower(strings.TrimSpace(in.Tier))
+	if tier == "" {
+		tier = s.cfg.DefaultTier
+	}
+	m, ok := s.cfg.Tiers[tier]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %s", ErrUnknownTier, tier)
+	}
+
+	res, err := s.planner.Plan(ctx, in.Request)
+	if err != nil {
+		return Quote{}, err
+	}
+	if !res.VWAP.IsPositive() || !res.Generated.IsPositive() {
+		return Quote{}, fmt.Errorf("недостаточно ликвидности для котировки %s/%s", res.Base, res.Quote)
+	}
+
+	q := Quote{
+		PlanID:      res.ID,
+		Tier:        tier,
+		Base:        res.Base,
+		Quote:       res.Quote,
+		Amount:      in.Amount,
+		Scenario:    res.Scenario,
+		MarketPrice: res.VWAP.Decimal,
+	}
+
+	// Покупка за USDT: цена — USDT за 1 BASE, для клиента она выше.
+	// Продажа и маршрут монета→монета: цена — сколько получаем за 1 QUOTE, для клиента ниже.
+	buy := !isUSDT(res.Base) && isUSDT(res.Quote)
+	bps := money.FromFloat(m.Bps).Shift(-4) // б.п. -> доля
+	fixed := money.FromFloat(m.Fixed)
+	if buy {
+		q.ClientPrice = money.Rate(res.VWAP.Mul(decimal.NewFromInt(1).Add(bps)).Add(fixed))
+		q.Receive = money.Amount(money.Div(res.TotalCost.Decimal, q.ClientPrice), res.Base)
+	} else {
+		q.ClientPrice = money.Rate(res.VWAP.Mul(decimal.NewFromInt(1).Sub(bps)).Sub(fixed))
+		if !q.ClientPrice.IsPositive() {
+			return Quote{}, fmt.Errorf("наценка уровня %s больше рыночной цены", tier)
+		}
+		q.Receive = money.Amount(res.TotalCost.Mul(q.ClientPrice), res.Base)
+	}
+	q.ReceiveUnit = res.Base // Generated всегда в BASE (при продаже BASE=USDT)
+	q.DeskMargin = res.Generated.Sub(q.Receive)
+
+	now := s.now().UTC().Truncate(time.Second)
+	q.IssuedAt = now
+	q.ExpiresAt = now.Add(s.cfg.Validity)
+	q.Signature = s.sign(q)
+	return q, nil
+}
+
+// Verify проверяет подпись и срок действия ранее выданной котировки.
+func (s *Service) Verify(q Quote) Verification {
+	if !hmac.Equal([]byte(s.sign(q)), []byte(strings.ToLower(strings.TrimSpace(q.Signature)))) {
+		return Verification{Reason: "signature mismatch"}
+	}
+	v := Verification{Signed: true}
+	left := q.ExpiresAt.Sub(s.now())
+	if left <= 0 {
+		v.Expired = true
+		v.Reason = "quote expired"
+		return v
+	}
+	v.Valid = true
+	v.ExpiresIn = left
+	return v
+}
+
+// sign — HMAC-SHA256 по каноничной строке полей, которые видит клиент.
+func (s *Service) sign(q Quote) string {
+	f := func(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
+	payload := strings.Join([]string{
+		"v3", // v3 — десятичные цены в записи decimal.String, без рыночной цены
+		q.PlanID,
+		q.Tier,
+		q.Base,
+		q.Quote,
+		f(q.Amount),
+		q.Scenario,
+		q.ClientPrice.String(),
+		q.Receive.String(),
+		q.ReceiveUnit,
+		strconv.FormatInt(q.IssuedAt.Unix(), 10),
+		strconv.FormatInt(q.ExpiresAt.Unix(), 10),
+	}, "|")
+	mac := hmac.New(sha256.New, s.cfg.Secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isUSDT(s string) bool { return strings.EqualFold(strings.TrimSpace(s), "USDT") }
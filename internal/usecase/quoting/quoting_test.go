@@ -0,0 +1,131 @@
+package quoting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/planner"
+)
+
+type fakePlanner struct{ res planner.Result }
+
+func (f fakePlanner) Plan(_ context.Context, in planner.Request) (planner.Result, error) {
+	r := f.res
+	r.Base, r.Quote, r.Scenario = in.Base, in.Quote, in.Scenario
+	return r, nil
+}
+
+func dec(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func num(s string) money.Number { return money.Num(dec(s)) }
+
+func newService(t *testing.T, res planner.Result, now time.Time) *Service {
+	t.Helper()
+	s, err := New(fakePlanner{res: res}, Config{
+		Secret:   []byte("secret"),
+		Validity: 30 * time.Second,
+		Tiers:    map[string]Markup{"default": {Bps: 100}, "vip": {Bps: 10}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestQuotePrices(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		base, quote string
+		res         planner.Result
+		tier        string
+		wantPrice   string
+		wantReceive string
+	}{
+		{
+			name: "buy raises price", base: "BTC", quote: "USDT", tier: "default",
+			res:       planner.Result{VWAP: num("100000"), TotalCost: num("1000"), Generated: num("0.01")},
+			wantPrice: "101000", wantReceive: "0.00990099",
+		},
+		{
+			name: "sell lowers price", base: "USDT", quote: "ETH", tier: "vip",
+			res:       planner.Result{VWAP: num("3000"), TotalCost: num("2"), Generated: num("6000")},
+			wantPrice: "2997", wantReceive: "5994",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newService(t, tt.res, now)
+			q, err := s.Quote(context.Background(), Request{
+				Request: planner.Request{Base: tt.base, Quote: tt.quote, Amount: 1},
+				Tier:    tt.tier,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !q.ClientPrice.Equal(dec(tt.wantPrice)) {
+				t.Errorf("ClientPrice = %s, want %s", q.ClientPrice, tt.wantPrice)
+			}
+			if !q.Receive.Equal(dec(tt.wantReceive)) {
+				t.Errorf("Receive = %s, want %s", q.Receive, tt.wantReceive)
+			}
+			if !q.DeskMargin.Equal(tt.res.Generated.Sub(q.Receive)) {
+				t.Errorf("DeskMargin = %s, want %s", q.DeskMargin, tt.res.Generated.Sub(q.Receive))
+			}
+		})
+	}
+}
+
+func TestQuoteUnknownTier(t *testing.T) {
+	s := newService(t, planner.Result{VWAP: num("1"), TotalCost: num("1"), Generated: num("1")}, time.Now())
+	_, err := s.Quote(context.Background(), Request{
+		Request: planner.Request{Base: "BTC", Quote: "USDT", Amount: 1},
+		Tier:    "gold",
+	})
+	if !errors.Is(err, ErrUnknownTier) {
+		t.Fatalf("err = %v, want ErrUnknownTier", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newService(t, planner.Result{VWAP: num("100000"), TotalCost: num("1000"), Generated: num("0.01")}, now)
+	q, err := s.Quote(context.Background(), Request{Request: planner.Request{Base: "BTC", Quote: "USDT", Amount: 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// клиент получает котировку без полей деска и присылает её обратно
+	client := q
+	client.MarketPrice, client.DeskMargin = decimal.Zero, decimal.Zero
+
+	tests := []struct {
+		name      string
+		q         Quote
+		at        time.Time
+		wantValid bool
+		wantSig   bool
+		wantExp   bool
+	}{
+		{name: "as issued", q: q, at: now, wantValid: true, wantSig: true},
+		{name: "without desk fields", q: client, at: now.Add(10 * time.Second), wantValid: true, wantSig: true},
+		{name: "expired", q: client, at: now.Add(time.Minute), wantSig: true, wantExp: true},
+		{name: "tampered price", q: func() Quote { c := client; c.ClientPrice = dec("99000"); return c }(), at: now},
+		{name: "tampered receive", q: func() Quote { c := client; c.Receive = dec("1"); return c }(), at: now},
+		{name: "tampered signature", q: func() Quote { c := client; c.Signature = "00" + c.Signature[2:]; return c }(), at: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.now = func() time.Time { return tt.at }
+			v := s.Verify(tt.q)
+			if v.Valid != tt.wantValid || v.Signed != tt.wantSig || v.Expired != tt.wantExp {
+				t.Fatalf("Verify = %+v, want valid=%v signed=%v expired=%v", v, tt.wantValid, tt.wantSig, tt.wantExp)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"container/heap"
+
+	"cryptobot/internal/domain"
+)
+
+// cursor — текущий уровень одной стороны стакана биржи.
+type cursor struct {
+	ex   string
+	side []domain.Order
+	next int // индекс следующего уровня
+
+	price, qty float64
+	mult       float64 // множитель цены для ранжирования (поправка на риск биржи)
+	rank       float64 // price * mult
+}
+
+// advance переходит к следующему непустому уровню; false — сторона кончилась.
+func (c *cursor) advance() bool {
+	for c.next < len(c.side) {
+		o := c.side[c.next]
+		c.next++
+		if o.Price <= 0 || o.Qty <= 0 {
+			continue
+		}
+		c.price, c.qty, c.rank = o.Price, o.Qty, o.Price*c.mult
+		return true
+	}
+	return false
+}
+
+// levelMerge — k-way слияние уровней всех бирж через кучу: сверху всегда лучший
+// по rank уровень (при покупке — самый дешёвый, при продаже — самый дорогой).
+// Стороны стаканов должны быть отсортированы от лучшей цены, как их отдают биржи;
+// стаканы с нарушенным порядком planner отсеивает заранее.
+type levelMerge struct {
+	cs   []*cursor
+	desc bool
+}
+
+// newLevelMerge собирает кучу по asks (Buy) или bids (Sell); mult — множитель
+// цены биржи для ранжирования.
+func newLevelMerge(books map[string]*domain.OrderBook, dir Direction, mult func(ex string) float64) *levelMerge {
+	m := &levelMerge{cs: make([]*cursor, 0, len(books)), desc: dir == Sell}
+	for ex, ob := range books {
+		if ob == nil {
+			continue
+		}
+		side := ob.Asks
+		if dir == Sell {
+			side = ob.Bids
+		}
+		c := &cursor{ex: ex, side: side, mult: mult(ex)}
+		if c.advance() {
+			m.cs = append(m.cs, c)
+		}
+	}
+	heap.Init(m)
+	return m
+}
+
+// top — лучший уровень; nil, если уровни кончились.
+func (m *levelMerge) top() *cursor {
+	if len(m.cs) == 0 {
+		return nil
+	}
+	return m.cs[0]
+}
+
+// pop — верхний уровень выбран целиком: переходим к следующему уровню той же биржи.
+func (m *levelMerge) pop() {
+	if m.cs[0].advance() {
+		heap.Fix(m, 0)
+		return
+	}
+	heap.Pop(m)
+}
+
+func (m *levelMerge) Len() int { return len(m.cs) }
+
+func (m *levelMerge) Less(i, j int) bool {
+	a, b := m.cs[i], m.cs[j]
+	if a.rank != b.rank {
+		return (a.rank < b.rank) != m.desc
+	}
+	return a.ex < b.ex // при равной цене — детерминированно по имени биржи
+}
+
+func (m *levelMerge) Swap(i, j int) { m.cs[i], m.cs[j] = m.cs[j], m.cs[i] }
+
+func (m *levelMerge) Push(x any) { m.cs = append(m.cs, x.(*cursor)) }
+
+func (m *levelMerge) Pop() any {
+	n := len(m.cs)
+	c := m.cs[n-1]
+	m.cs = m.cs[:n-1]
+	return c
+}
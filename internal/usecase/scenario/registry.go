@@ -0,0 +1,85 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Strategy — сценарий распределения заявки по биржам.
+type Strategy interface {
+	Name() string
+	Run(in Inputs) Result
+}
+
+// Text — строка интерфейса на поддерживаемых языках.
+type Text struct {
+	RU string `json:"ru"`
+	EN string `json:"en"`
+}
+
+// Info — описание сценария для списков и интерфейса.
+type Info struct {
+	ID          string `json:"id"` // идентификатор в API и конфиге: best_single, optimal, ...
+	Title       Text   `json:"title"`
+	Description Text   `json:"description"`
+}
+
+// Entry — зарегистрированный сценарий.
+type Entry struct {
+	Info
+	Strategy Strategy
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Entry
+)
+
+// Register добавляет сценарий в реестр; вызывается из init файла сценария.
+// Порядок регистрации — порядок показа: встроенные сценарии регистрируются
+// в порядке имён файлов (s1_, s2_, ...), и первый служит базой для сравнения.
+func Register(info Info, s Strategy) {
+	info.ID = strings.ToLower(strings.TrimSpace(info.ID))
+	if info.ID == "" || s == nil {
+		panic("scenario: Register with empty id or nil strategy")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, e := range registry {
+		if e.ID == info.ID {
+			panic(fmt.Sprintf("scenario: %q registered twice", info.ID))
+		}
+	}
+	registry = append(registry, Entry{Info: info, Strategy: s})
+}
+
+// All — зарегистрированные сценарии в порядке показа.
+func All() []Entry {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Entry(nil), registry...)
+}
+
+// IDs — идентификаторы сценариев в порядке показа.
+func IDs() []string {
+	all := All()
+	ids := make([]string, 0, len(all))
+	for _, e := range all {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// Lookup — сценарий по идентификатору (без учёта регистра).
+func Lookup(id string) (Entry, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, e := range registry {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
@@ -3,24 +3,35 @@ package scenario
 import (
 	"sort"
 
+	"github.com/shopspring/decimal"
+
 	"cryptobot/internal/usecase/orderbook"
 )
 
 type BestSingle struct{}
 
+func init() {
+	Register(Info{
+		ID:          "best_single",
+		Title:       Text{RU: "Лучшая одиночная", EN: "Best single"},
+		Description: Text{RU: "Вся сумма уходит на одну биржу с наилучшей ценой", EN: "All funds go to the single exchange with the best price"},
+	}, BestSingle{})
+}
+
 func (BestSingle) Name() string {
 	return "Сценарий #1 (Самая выгодная биржа)"
 }
 
 func (BestSingle) Run(in Inputs) Result {
+	in.OrderBooks = in.FreshBooks() // устаревшие стаканы в расчёт не идут
 	res := Result{Asset: in.Right}
 
 	type cand struct {
 		ex    string
-		qty   float64
-		avg   float64
-		net   float64
-		obQty float64
+		qty   decimal.Decimal
+		avg   decimal.Decimal
+		net   decimal.Decimal
+		obQty decimal.Decimal
 	}
 
 	var cs []cand
@@ -33,14 +44,14 @@ func (BestSingle) Run(in Inputs) Result {
 		switch in.Direction {
 		case Buy:
 			qty, avg, spent := orderbook.BuyQtyFromAsks(ob.Asks, in.Amount)
-			if qty <= 0 || avg <= 0 || spent <= 0 {
+			if !qty.IsPositive() || !avg.IsPositive() || !spent.IsPositive() {
 				continue
 			}
 			cs = append(cs, cand{ex: ex, qty: qty, avg: avg, net: spent, obQty: qty})
 
 		case Sell:
 			received, avg := orderbook.SellFromBids(ob.Bids, in.Amount)
-			if received <= 0 || avg <= 0 {
+			if !received.IsPositive() || !avg.IsPositive() {
 				continue
 			}
 			cs = append(cs, cand{ex: ex, qty: in.Amount, avg: avg, net: received, obQty: in.Amount})
@@ -51,53 +62,36 @@ func (BestSingle) Run(in Inputs) Result {
 		return res
 	}
 
-	bestSlice := append([]cand(nil), cs...)
-	if in.Direction == Buy {
-		sort.Slice(bestSlice, func(i, j int) bool {
-			if bestSlice[i].avg == bestSlice[j].avg {
-				return bestSlice[i].obQty > bestSlice[j].obQty
-			}
-			return bestSlice[i].avg < bestSlice[j].avg
-		})
-	} else {
-		sort.Slice(bestSlice, func(i, j int) bool {
-			if bestSlice[i].avg == bestSlice[j].avg {
-				return bestSlice[i].obQty > bestSlice[j].obQty
-			}
-			return bestSlice[i].avg > bestSlice[j].avg
-		})
-	}
-	best := bestSlice[0]
-
 	if in.Direction == Buy {
 		sort.Slice(cs, func(i, j int) bool {
-			if cs[i].avg == cs[j].avg {
-				return cs[i].obQty > cs[j].obQty
+			if cs[i].avg.Equal(cs[j].avg) {
+				return cs[i].obQty.GreaterThan(cs[j].obQty)
 			}
-			return cs[i].avg < cs[j].avg
+			return cs[i].avg.LessThan(cs[j].avg)
 		})
 	} else {
 		sort.Slice(cs, func(i, j int) bool {
-			if cs[i].avg == cs[j].avg {
-				return cs[i].obQty > cs[j].obQty
+			if cs[i].avg.Equal(cs[j].avg) {
+				return cs[i].obQty.GreaterThan(cs[j].obQty)
 			}
-			return cs[i].avg > cs[j].avg
-		})
-	}
-	for _, c := range cs {
-		res.Legs = append(res.Legs, Leg{
-			Exchange:   c.ex,
-			Price:      c.avg,
-			Qty:        c.qty,
-			AmountUSDT: c.net,
+			return cs[i].avg.GreaterThan(cs[j].avg)
 		})
 	}
+	best := cs[0]
+
+	// Ножка одна — выбранная биржа: остальные кандидаты не исполняются
+	res.Legs = []Leg{{
+		Exchange:   best.ex,
+		Price:      best.avg,
+		Qty:        best.qty,
+		AmountUSDT: best.net,
+	}}
 
 	if in.Direction == Buy {
 		res.TotalQty = best.qty
 		res.TotalUSDT = best.net
 		res.AveragePrice = best.avg
-		res.Leftover = in.Amount - best.net
+		res.Leftover = in.Amount.Sub(best.net)
 		res.Asset = in.Right
 	} else {
 		res.TotalQty = in.Amount
@@ -3,16 +3,28 @@ package scenario
 import (
 	"sort"
 
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
 	"cryptobot/internal/usecase/orderbook"
 )
 
 type EqualSplit struct{}
 
+func init() {
+	Register(Info{
+		ID:          "equal_split",
+		Title:       Text{RU: "Равное распределение", EN: "Equal split"},
+		Description: Text{RU: "Сумма делится равными частями между всеми биржами", EN: "Funds are split equally across all exchanges"},
+	}, EqualSplit{})
+}
+
 func (EqualSplit) Name() string {
 	return "Сценарий #2 (Равное распределение средств по биржам)"
 }
 
 func (EqualSplit) Run(in Inputs) Result {
+	in.OrderBooks = in.FreshBooks() // устаревшие стаканы в расчёт не идут
 	res := Result{Asset: in.Right}
 
 	type exOB struct {
@@ -29,7 +41,8 @@ func (EqualSplit) Run(in Inputs) Result {
 	}
 	sort.Slice(xs, func(i, j int) bool { return xs[i].ex < xs[j].ex })
 
-	split := in.Amount / float64(len(xs))
+	// доля округляется вниз: сумма долей не больше заявки
+	split, _ := in.Amount.QuoRem(decimal.NewFromInt(int64(len(xs))), money.DivScale)
 
 	switch in.Direction {
 	case Buy:
@@ -39,7 +52,7 @@ func (EqualSplit) Run(in Inputs) Result {
 				continue
 			}
 			qty, avg, spent := orderbook.BuyQtyFromAsks(ob.Asks, split)
-			if qty <= 0 || avg <= 0 || spent <= 0 {
+			if !qty.IsPositive() || !avg.IsPositive() || !spent.IsPositive() {
 				continue
 			}
 			res.Legs = append(res.Legs, Leg{
@@ -48,8 +61,8 @@ func (EqualSplit) Run(in Inputs) Result {
 				Qty:        qty,
 				AmountUSDT: spent,
 			})
-			res.TotalQty += qty
-			res.TotalUSDT += spent
+			res.TotalQty = res.TotalQty.Add(qty)
+			res.TotalUSDT = res.TotalUSDT.Add(spent)
 		}
 
 	case Sell:
@@ -59,7 +72,7 @@ func (EqualSplit) Run(in Inputs) Result {
 				continue
 			}
 			received, avg := orderbook.SellFromBids(ob.Bids, split)
-			if received <= 0 || avg <= 0 {
+			if !received.IsPositive() || !avg.IsPositive() {
 				continue
 			}
 			res.Legs = append(res.Legs, Leg{
@@ -68,14 +81,12 @@ func (EqualSplit) Run(in Inputs) Result {
 				Qty:        split,
 				AmountUSDT: received,
 			})
-			res.TotalQty += split
-			res.TotalUSDT += received
+			res.TotalQty = res.TotalQty.Add(split)
+			res.TotalUSDT = res.TotalUSDT.Add(received)
 		}
 	}
 
-	if res.TotalQty > 0 {
-		res.AveragePrice = res.TotalUSDT / res.TotalQty
-	}
+	res.AveragePrice = money.Div(res.TotalUSDT, res.TotalQty)
 	if in.Direction == Sell {
 		res.Asset = in.Symbol[:len(in.Symbol)-4]
 	}
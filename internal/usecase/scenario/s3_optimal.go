@@ -2,156 +2,121 @@ package scenario
 
 import (
 	"sort"
-	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/shared/money"
 )
 
 type Optimal struct{}
 
+func init() {
+	Register(Info{
+		ID:          "optimal",
+		Title:       Text{RU: "Оптимально", EN: "Optimal"},
+		Description: Text{RU: "Сумма распределяется оптимально между биржами для лучшей цены", EN: "Funds are distributed optimally across exchanges for best execution"},
+	}, Optimal{})
+}
+
 func (Optimal) Name() string {
 	return "Сценарий #3 (Лучшее распределение средств)"
 }
 
 func (Optimal) Run(in Inputs) Result {
+	in.OrderBooks = in.FreshBooks() // устаревшие стаканы в расчёт не идут
 	res := Result{Asset: in.Right}
+	if in.Direction == Sell {
+		// Для SELL Asset — левая часть символа (без суффикса USDT)
+		res.Asset = strings.TrimSuffix(in.Symbol, "USDT")
+	}
+	if in.Direction != Buy && in.Direction != Sell {
+		return res
+	}
 
+	// Уровни ранжируются во float64 (слияние стаканов), а исполнение считается
+	// в decimal: в десятичные переводятся только взятые уровни, так что суммы
+	// ножек и итоги сходятся с заявкой точно.
 	type legAgg struct {
-		qty  float64
-		usdt float64
+		qty  decimal.Decimal
+		usdt decimal.Decimal
 	}
-
 	legsByEx := map[string]*legAgg{}
 
-	add := func(ex string, price, qty float64) {
-		if qty <= 0 || price <= 0 {
+	// штраф за риск биржи: уровни ранжируются по цене с поправкой,
+	// исполняются — по цене стакана
+	mult := func(ex string) float64 {
+		r := in.Params.RiskBps[strings.ToLower(ex)] / 10000
+		if in.Direction == Sell {
+			return 1 - r
+		}
+		return 1 + r
+	}
+	var adjUSDT decimal.Decimal
+
+	add := func(c *cursor, price, qty decimal.Decimal) {
+		if !qty.IsPositive() {
 			return
 		}
-		l := legsByEx[ex]
+		l := legsByEx[c.ex]
 		if l == nil {
 			l = &legAgg{}
-			legsByEx[ex] = l
+			legsByEx[c.ex] = l
 		}
-		l.qty += qty
-		l.usdt += price * qty
-		res.TotalQty += qty
-		res.TotalUSDT += price * qty
+		usdt := price.Mul(qty)
+		l.qty = l.qty.Add(qty)
+		l.usdt = l.usdt.Add(usdt)
+		adjUSDT = adjUSDT.Add(usdt.Mul(money.FromFloat(c.mult)))
 	}
 
-	switch in.Direction {
-	case Buy:
-		// Собираем все аски всех бирж в единый массив (ex, price, qty)
-		type level struct {
-			ex    string
-			price float64
-			qty   float64
-		}
-		var all []level
-		for ex, ob := range in.OrderBooks {
-			if ob == nil {
-				continue
-			}
-			for _, a := range ob.Asks {
-				p, err1 := strconv.ParseFloat(a.Price, 64)
-				q, err2 := strconv.ParseFloat(a.Quantity, 64)
-				if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
-					continue
-				}
-				all = append(all, level{ex: ex, price: p, qty: q})
-			}
-		}
-		sort.Slice(all, func(i, j int) bool { return all[i].price < all[j].price })
-
-		remainBudget := in.Amount
-		for _, lv := range all {
-			if remainBudget <= 0 {
-				break
-			}
-			maxCost := lv.price * lv.qty
-			if maxCost <= remainBudget {
-				add(lv.ex, lv.price, lv.qty)
-				remainBudget -= maxCost
-			} else {
-				q := remainBudget / lv.price
-				if q > 0 {
-					add(lv.ex, lv.price, q)
-					remainBudget = 0
-				}
-				break
-			}
-		}
-		res.Leftover = in.Amount - res.TotalUSDT
-		res.Asset = in.Right
-
-	case Sell:
-		type level struct {
-			ex    string
-			price float64
-			qty   float64
+	// идём по уровням всех бирж от лучшего и останавливаемся, как только заявка исполнена
+	m := newLevelMerge(in.OrderBooks, in.Direction, mult)
+	remain := in.Amount // USDT при покупке, монета при продаже
+	for c := m.top(); c != nil && remain.IsPositive(); c = m.top() {
+		price, qty := money.FromFloat(c.price), money.FromFloat(c.qty)
+		full := qty // сколько уровень вмещает в единицах заявки
+		if in.Direction == Buy {
+			full = price.Mul(qty)
 		}
-		var all []level
-		for ex, ob := range in.OrderBooks {
-			if ob == nil {
-				continue
-			}
-			for _, b := range ob.Bids {
-				p, err1 := strconv.ParseFloat(b.Price, 64)
-				q, err2 := strconv.ParseFloat(b.Quantity, 64)
-				if err1 != nil || err2 != nil || p <= 0 || q <= 0 {
-					continue
-				}
-				all = append(all, level{ex: ex, price: p, qty: q})
-			}
-		}
-
-		sort.Slice(all, func(i, j int) bool { return all[i].price > all[j].price })
-
-		remainQty := in.Amount
-		for _, lv := range all {
-			if remainQty <= 0 {
-				break
-			}
-			if lv.qty <= remainQty {
-				add(lv.ex, lv.price, lv.qty)
-				remainQty -= lv.qty
+		if full.GreaterThan(remain) {
+			if in.Direction == Buy {
+				// доля монеты округляется вниз, чтобы не выйти за бюджет
+				take, _ := remain.QuoRem(price, money.DivScale)
+				add(c, price, take)
 			} else {
-				add(lv.ex, lv.price, remainQty)
-				remainQty = 0
-				break
+				add(c, price, remain)
 			}
+			break
 		}
-		// Для SELL Asset — левая часть символа (без суффикса USDT)
-		if len(in.Symbol) > 4 {
-			res.Asset = in.Symbol[:len(in.Symbol)-4]
-		} else {
-			res.Asset = in.Symbol
-		}
-	default:
-		return res
+		add(c, price, qty)
+		remain = remain.Sub(full)
+		m.pop()
 	}
 
 	// Цена в ноге — средневзвешенная (usdt/qty)
-	type exName struct{ name string }
-	var keys []exName
+	keys := make([]string, 0, len(legsByEx))
 	for ex := range legsByEx {
-		keys = append(keys, exName{name: ex})
+		keys = append(keys, ex)
 	}
-	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
-
-	for _, k := range keys {
-		l := legsByEx[k.name]
-		if l == nil || l.qty <= 0 {
-			continue
-		}
-		avg := l.usdt / l.qty
+	sort.Strings(keys)
+	for _, ex := range keys {
+		l := legsByEx[ex]
 		res.Legs = append(res.Legs, Leg{
-			Exchange:   k.name,
-			Price:      avg,
+			Exchange:   ex,
+			Price:      money.Div(l.usdt, l.qty),
 			Qty:        l.qty,
 			AmountUSDT: l.usdt,
 		})
+		res.TotalQty = res.TotalQty.Add(l.qty)
+		res.TotalUSDT = res.TotalUSDT.Add(l.usdt)
+	}
+	if in.Direction == Buy {
+		res.Leftover = in.Amount.Sub(res.TotalUSDT)
 	}
 
-	if res.TotalQty > 0 {
-		res.AveragePrice = res.TotalUSDT / res.TotalQty
+	res.AveragePrice = money.Div(res.TotalUSDT, res.TotalQty)
+	if len(in.Params.RiskBps) > 0 {
+		res.RiskAdjustedUSDT = adjUSDT
 	}
 	return res
 }
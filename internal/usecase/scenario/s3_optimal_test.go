@@ -0,0 +1,305 @@
+package scenario
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
+)
+
+// randBooks — venues стаканов по levels уровней вокруг 100 USDT. Цены округлены
+// до центов, поэтому у бирж часто совпадают уровни — проверяется и порядок при равной цене.
+func randBooks(r *rand.Rand, venues, levels int) map[string]*domain.OrderBook {
+	books := make(map[string]*domain.OrderBook, venues)
+	for v := 0; v < venues; v++ {
+		ex := fmt.Sprintf("ex%d", v)
+		ob := &domain.OrderBook{Exchange: ex, Symbol: "BTCUSDT"}
+		ask, bid := 100+float64(r.Intn(20))/100, 99.9-float64(r.Intn(20))/100
+		for i := 0; i < levels; i++ {
+			ob.Asks = append(ob.Asks, domain.Order{Price: math.Round(ask*100) / 100, Qty: float64(1+r.Intn(5000)) / 1000})
+			ob.Bids = append(ob.Bids, domain.Order{Price: math.Round(bid*100) / 100, Qty: float64(1+r.Intn(5000)) / 1000})
+			ask += float64(r.Intn(3)) / 100
+			bid -= float64(r.Intn(3)) / 100
+		}
+		books[ex] = ob
+	}
+	return books
+}
+
+// level — уровень стакана для эталонных путей: полная сортировка вместо слияния.
+type level struct {
+	ex         string
+	price, qty float64
+	mult, rank float64
+}
+
+// sortedLevels — все уровни всех бирж одним срезом от лучшего по цене с поправкой
+// на риск; при равной цене — по имени биржи, как в слиянии.
+func sortedLevels(in Inputs) []level {
+	mult := func(ex string) float64 {
+		r := in.Params.RiskBps[strings.ToLower(ex)] / 10000
+		if in.Direction == Sell {
+			return 1 - r
+		}
+		return 1 + r
+	}
+	names := make([]string, 0, len(in.OrderBooks))
+	for ex := range in.OrderBooks {
+		names = append(names, ex)
+	}
+	sort.Strings(names)
+	var all []level
+	for _, ex := range names {
+		side := in.OrderBooks[ex].Asks
+		if in.Direction == Sell {
+			side = in.OrderBooks[ex].Bids
+		}
+		m := mult(ex)
+		for _, o := range side {
+			if o.Price > 0 && o.Qty > 0 {
+				all = append(all, level{ex: ex, price: o.Price, qty: o.Qty, mult: m, rank: o.Price * m})
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].rank != all[j].rank {
+			return (all[i].rank < all[j].rank) != (in.Direction == Sell)
+		}
+		return all[i].ex < all[j].ex
+	})
+	return all
+}
+
+// sortFill — прежний путь Optimal: полная сортировка уровней и жадное заполнение.
+// Арифметика заполнения та же, что у Optimal, поэтому результаты должны совпадать точно.
+func sortFill(in Inputs) Result {
+	type agg struct{ qty, usdt decimal.Decimal }
+	by := map[string]*agg{}
+	var adj decimal.Decimal
+	add := func(lv level, price, qty decimal.Decimal) {
+		if !qty.IsPositive() {
+			return
+		}
+		if by[lv.ex] == nil {
+			by[lv.ex] = &agg{}
+		}
+		usdt := price.Mul(qty)
+		by[lv.ex].qty = by[lv.ex].qty.Add(qty)
+		by[lv.ex].usdt = by[lv.ex].usdt.Add(usdt)
+		adj = adj.Add(usdt.Mul(money.FromFloat(lv.mult)))
+	}
+	remain := in.Amount
+	for _, lv := range sortedLevels(in) {
+		if !remain.IsPositive() {
+			break
+		}
+		price, qty := money.FromFloat(lv.price), money.FromFloat(lv.qty)
+		full := qty
+		if in.Direction == Buy {
+			full = price.Mul(qty)
+		}
+		if full.GreaterThan(remain) {
+			if in.Direction == Buy {
+				take, _ := remain.QuoRem(price, money.DivScale)
+				add(lv, price, take)
+			} else {
+				add(lv, price, remain)
+			}
+			break
+		}
+		add(lv, price, qty)
+		remain = remain.Sub(full)
+	}
+
+	names := make([]string, 0, len(by))
+	for ex := range by {
+		names = append(names, ex)
+	}
+	sort.Strings(names)
+	res := Result{}
+	for _, ex := range names {
+		a := by[ex]
+		res.Legs = append(res.Legs, Leg{Exchange: ex, Price: money.Div(a.usdt, a.qty), Qty: a.qty, AmountUSDT: a.usdt})
+		res.TotalQty = res.TotalQty.Add(a.qty)
+		res.TotalUSDT = res.TotalUSDT.Add(a.usdt)
+	}
+	if in.Direction == Buy {
+		res.Leftover = in.Amount.Sub(res.TotalUSDT)
+	}
+	res.AveragePrice = money.Div(res.TotalUSDT, res.TotalQty)
+	if len(in.Params.RiskBps) > 0 {
+		res.RiskAdjustedUSDT = adj
+	}
+	return res
+}
+
+// floatFill — то же заполнение во float64 (как Optimal считал до перехода на decimal):
+// итоги по биржам и сумма с поправкой на риск.
+func floatFill(in Inputs) (legs map[string][2]float64, adj float64) {
+	legs = map[string][2]float64{}
+	add := func(lv level, qty float64) {
+		l := legs[lv.ex]
+		legs[lv.ex] = [2]float64{l[0] + qty, l[1] + lv.price*qty}
+		adj += lv.price * qty * lv.mult
+	}
+	remain := in.Amount.InexactFloat64()
+	for _, lv := range sortedLevels(in) {
+		if remain <= 0 {
+			break
+		}
+		full := lv.qty
+		if in.Direction == Buy {
+			full = lv.price * lv.qty
+		}
+		if full > remain {
+			if in.Direction == Buy {
+				add(lv, remain/lv.price)
+			} else {
+				add(lv, remain)
+			}
+			break
+		}
+		add(lv, lv.qty)
+		remain -= full
+	}
+	return legs, adj
+}
+
+func sameResult(t *testing.T, got, want Result) {
+	t.Helper()
+	eq := func(name string, a, b decimal.Decimal) {
+		if !a.Equal(b) {
+			t.Errorf("%s = %s, want %s", name, a, b)
+		}
+	}
+	eq("TotalQty", got.TotalQty, want.TotalQty)
+	eq("TotalUSDT", got.TotalUSDT, want.TotalUSDT)
+	eq("AveragePrice", got.AveragePrice, want.AveragePrice)
+	eq("Leftover", got.Leftover, want.Leftover)
+	eq("RiskAdjustedUSDT", got.RiskAdjustedUSDT, want.RiskAdjustedUSDT)
+	if len(got.Legs) != len(want.Legs) {
+		t.Fatalf("legs = %+v, want %+v", got.Legs, want.Legs)
+	}
+	for i := range want.Legs {
+		g, w := got.Legs[i], want.Legs[i]
+		if g.Exchange != w.Exchange {
+			t.Fatalf("leg %d exchange = %s, want %s", i, g.Exchange, w.Exchange)
+		}
+		eq(g.Exchange+" Qty", g.Qty, w.Qty)
+		eq(g.Exchange+" AmountUSDT", g.AmountUSDT, w.AmountUSDT)
+		eq(g.Exchange+" Price", g.Price, w.Price)
+	}
+}
+
+func TestOptimalMatchesSortFill(t *testing.T) {
+	risk := map[string]float64{"ex1": 15, "ex3": 40}
+	for seed := int64(1); seed <= 40; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		books := randBooks(r, 1+r.Intn(7), 1+r.Intn(60))
+		for _, dir := range []Direction{Buy, Sell} {
+			// от части первого уровня до суммы больше всей видимой глубины
+			amount := decimal.NewFromFloat(float64(1+r.Intn(40000)) / 100)
+			if dir == Sell {
+				amount = decimal.NewFromFloat(float64(1+r.Intn(40000)) / 1000)
+			}
+			for _, rb := range []map[string]float64{nil, risk} {
+				name := fmt.Sprintf("seed=%d/dir=%d/risk=%v", seed, dir, rb != nil)
+				t.Run(name, func(t *testing.T) {
+					in := Inputs{Direction: dir, Symbol: "BTCUSDT", Right: "BTC", Amount: amount, OrderBooks: books, Params: Params{RiskBps: rb}}
+					sameResult(t, Optimal{}.Run(in), sortFill(in))
+				})
+			}
+		}
+	}
+}
+
+// Decimal-путь совпадает с float-путём до погрешности float64, но в отличие от него
+// сходится точно: итоги — сумма ножек, а потрачено + остаток — ровно заявка.
+func TestOptimalMatchesFloat(t *testing.T) {
+	near := func(a decimal.Decimal, b float64) bool {
+		return math.Abs(a.InexactFloat64()-b) <= 1e-9*math.Max(1, math.Abs(b))
+	}
+	risk := map[string]float64{"ex1": 15, "ex3": 40}
+	for seed := int64(1); seed <= 40; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		books := randBooks(r, 1+r.Intn(7), 1+r.Intn(60))
+		for _, dir := range []Direction{Buy, Sell} {
+			amount := decimal.NewFromFloat(float64(1+r.Intn(40000)) / 100)
+			if dir == Sell {
+				amount = decimal.NewFromFloat(float64(1+r.Intn(40000)) / 1000)
+			}
+			t.Run(fmt.Sprintf("seed=%d/dir=%d", seed, dir), func(t *testing.T) {
+				in := Inputs{Direction: dir, Symbol: "BTCUSDT", Right: "BTC", Amount: amount, OrderBooks: books, Params: Params{RiskBps: risk}}
+				got := Optimal{}.Run(in)
+				legs, adj := floatFill(in)
+				if len(got.Legs) != len(legs) {
+					t.Fatalf("legs = %+v, float legs = %v", got.Legs, legs)
+				}
+				var qty, usdt decimal.Decimal
+				for _, l := range got.Legs {
+					want := legs[l.Exchange]
+					if !near(l.Qty, want[0]) || !near(l.AmountUSDT, want[1]) {
+						t.Errorf("%s: qty %s usdt %s, float %v %v", l.Exchange, l.Qty, l.AmountUSDT, want[0], want[1])
+					}
+					qty, usdt = qty.Add(l.Qty), usdt.Add(l.AmountUSDT)
+				}
+				if !near(got.RiskAdjustedUSDT, adj) {
+					t.Errorf("RiskAdjustedUSDT = %s, float %v", got.RiskAdjustedUSDT, adj)
+				}
+				if !got.TotalQty.Equal(qty) || !got.TotalUSDT.Equal(usdt) {
+					t.Errorf("totals %s/%s != sum of legs %s/%s", got.TotalQty, got.TotalUSDT, qty, usdt)
+				}
+				switch {
+				case dir == Buy && (got.Leftover.IsNegative() || !got.TotalUSDT.Add(got.Leftover).Equal(amount)):
+					t.Errorf("spent %s + leftover %s != amount %s", got.TotalUSDT, got.Leftover, amount)
+				case dir == Sell && got.TotalQty.GreaterThan(amount):
+					t.Errorf("sold %s > amount %s", got.TotalQty, amount)
+				}
+			})
+		}
+	}
+}
+
+func TestOptimalEmpty(t *testing.T) {
+	in := Inputs{Direction: Buy, Symbol: "BTCUSDT", Right: "BTC", Amount: decimal.NewFromInt(100), OrderBooks: map[string]*domain.OrderBook{
+		"a": {Exchange: "a"},
+		"b": nil,
+	}}
+	res := Optimal{}.Run(in)
+	if len(res.Legs) != 0 || !res.TotalQty.IsZero() || !res.Leftover.Equal(in.Amount) {
+		t.Fatalf("res = %+v", res)
+	}
+}
+
+// BenchmarkOptimal — 7 бирж по 5000 уровней: k-way слияние против полной сортировки.
+func BenchmarkOptimal(b *testing.B) {
+	books := randBooks(rand.New(rand.NewSource(1)), 7, 5000)
+	for _, bc := range []struct {
+		name   string
+		amount float64 // USDT
+	}{
+		{"small", 50_000},    // несколько десятков уровней
+		{"large", 5_000_000}, // заметная часть всей глубины
+	} {
+		in := Inputs{Direction: Buy, Symbol: "BTCUSDT", Right: "BTC", Amount: decimal.NewFromFloat(bc.amount), OrderBooks: books}
+		b.Run(bc.name+"/merge", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Optimal{}.Run(in)
+			}
+		})
+		b.Run(bc.name+"/sort", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sortFill(in)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package scenario
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/usecase/orderbook"
+)
+
+// LiquiditySplit делит заявку пропорционально глубине бирж в ценовой полосе
+// от лучшей цены по всем биржам. Что не поместилось — раздаётся заново по оставшейся
+// глубине, пока заявка не исполнена или стаканы не выбраны.
+type LiquiditySplit struct {
+	BandPct float64 // ширина полосы от лучшей цены, %; 0 — берётся Inputs.Params, затем умолчание
+}
+
+// defaultLiquidityBandPct — полоса по умолчанию, %.
+const defaultLiquidityBandPct = 1.0
+
+func init() {
+	Register(Info{
+		ID:          "liquidity_split",
+		Title:       Text{RU: "По ликвидности", EN: "Liquidity split"},
+		Description: Text{RU: "Сумма делится пропорционально глубине стаканов у лучшей цены", EN: "Funds are split in proportion to order book depth near the best price"},
+	}, LiquiditySplit{})
+}
+
+func (LiquiditySplit) Name() string {
+	return "Сценарий #4 (Распределение по ликвидности)"
+}
+
+func (s LiquiditySplit) bandPct(in Inputs) float64 {
+	switch {
+	case s.BandPct > 0:
+		return s.BandPct
+	case in.Params.LiquidityBandPct > 0:
+		return in.Params.LiquidityBandPct
+	}
+	return defaultLiquidityBandPct
+}
+
+func (s LiquiditySplit) Run(in Inputs) Result {
+	in.OrderBooks = in.FreshBooks() // устаревшие стаканы в расчёт не идут
+	res := Result{Asset: in.Right}
+	if in.Direction == Sell {
+		res.Asset = in.Symbol[:len(in.Symbol)-4]
+	}
+
+	// сторона стакана, по которой исполняется заявка: аски при покупке, биды при продаже
+	type venue struct {
+		ex     string
+		side   []domain.Order
+		levels []domain.Order
+		band   decimal.Decimal // ёмкость в полосе, в единицах заявки (USDT при покупке, монета при продаже)
+		total  decimal.Decimal // ёмкость всей видимой стороны
+		alloc  decimal.Decimal
+	}
+	var vs []*venue
+	for ex, ob := range in.OrderBooks {
+		if ob == nil {
+			continue
+		}
+		side := ob.Asks
+		if in.Direction == Sell {
+			side = ob.Bids
+		}
+		if lv := nonEmpty(side); len(lv) > 0 {
+			vs = append(vs, &venue{ex: ex, side: side, levels: lv})
+		}
+	}
+	if len(vs) == 0 || !in.Amount.IsPositive() || (in.Direction != Buy && in.Direction != Sell) {
+		return res
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i].ex < vs[j].ex })
+
+	// лучшая цена по всем биржам и граница полосы
+	best := vs[0].levels[0].Price
+	for _, v := range vs[1:] {
+		p := v.levels[0].Price
+		if (in.Direction == Buy && p < best) || (in.Direction == Sell && p > best) {
+			best = p
+		}
+	}
+	band := s.bandPct(in) / 100
+	inBand := func(p float64) bool {
+		if in.Direction == Buy {
+			return p <= best*(1+band)
+		}
+		return p >= best*(1-band)
+	}
+	for _, v := range vs {
+		for _, l := range v.levels {
+			amt := money.FromFloat(l.Qty) // при продаже заявка в монете
+			if in.Direction == Buy {
+				amt = money.FromFloat(l.Price).Mul(amt)
+			}
+			v.total = v.total.Add(amt)
+			if inBand(l.Price) {
+				v.band = v.band.Add(amt)
+			}
+		}
+	}
+
+	// раздаём остаток пропорционально ёмкости в полосе; когда полоса выбрана —
+	// пропорционально оставшейся глубине. Каждый круг либо раздаёт всё, либо
+	// выбирает ёмкость, по которой делили, так что кругов немного. Доли округляются
+	// вниз, поэтому их сумма не превышает заявку; круг, который ничего не раздал, — последний.
+	remain := in.Amount
+	for remain.IsPositive() {
+		weight := func(v *venue) decimal.Decimal { return decimal.Max(v.band.Sub(v.alloc), decimal.Zero) }
+		var sum decimal.Decimal
+		for _, v := range vs {
+			sum = sum.Add(weight(v))
+		}
+		if !sum.IsPositive() {
+			weight = func(v *venue) decimal.Decimal { return decimal.Max(v.total.Sub(v.alloc), decimal.Zero) }
+			for _, v := range vs {
+				sum = sum.Add(weight(v))
+			}
+		}
+		if !sum.IsPositive() {
+			break // все стаканы выбраны
+		}
+		var given decimal.Decimal
+		for _, v := range vs {
+			w := weight(v)
+			share, _ := remain.Mul(w).QuoRem(sum, money.DivScale)
+			share = decimal.Min(share, w)
+			v.alloc = v.alloc.Add(share)
+			given = given.Add(share)
+		}
+		if !given.IsPositive() {
+			break
+		}
+		remain = remain.Sub(given)
+	}
+
+	for _, v := range vs {
+		if !v.alloc.IsPositive() {
+			continue
+		}
+		var leg Leg
+		switch in.Direction {
+		case Buy:
+			qty, avg, spent := orderbook.BuyQtyFromAsks(v.side, v.alloc)
+			if !qty.IsPositive() || !avg.IsPositive() || !spent.IsPositive() {
+				continue
+			}
+			leg = Leg{Exchange: v.ex, Price: avg, Qty: qty, AmountUSDT: spent}
+		case Sell:
+			received, avg := orderbook.SellFromBids(v.side, v.alloc)
+			if !received.IsPositive() || !avg.IsPositive() {
+				continue
+			}
+			leg = Leg{Exchange: v.ex, Price: avg, Qty: v.alloc, AmountUSDT: received}
+		}
+		res.Legs = append(res.Legs, leg)
+		res.TotalQty = res.TotalQty.Add(leg.Qty)
+		res.TotalUSDT = res.TotalUSDT.Add(leg.AmountUSDT)
+	}
+
+	res.AveragePrice = money.Div(res.TotalUSDT, res.TotalQty)
+	if in.Direction == Buy {
+		res.Leftover = decimal.Max(in.Amount.Sub(res.TotalUSDT), decimal.Zero)
+	}
+	return res
+}
+
+// nonEmpty — уровни стороны без пустых (нулевой объём).
+func nonEmpty(side []domain.Order) []domain.Order {
+	out := make([]domain.Order, 0, len(side))
+	for _, o := range side {
+		if o.Price > 0 && o.Qty > 0 {
+			out = append(out, o)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package scenario
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"cryptobot/internal/domain"
+)
+
+// MinVenues ищет наименьший набор бирж, на котором оптимальное распределение
+// хуже Optimal по всем биржам не больше чем на допуск по VWAP: меньше бирж —
+// меньше ордеров и переводов.
+type MinVenues struct {
+	SlippageBps float64 // допуск к VWAP Optimal, б.п.; 0 — берётся Inputs.Params, затем умолчание
+}
+
+// VenueSelection — выбранный набор бирж и цена отказа от остальных.
+type VenueSelection struct {
+	Exchanges    []string
+	ToleranceBps float64
+	OptimalVWAP  decimal.Decimal // VWAP Optimal по всем биржам
+	CostDiffUSDT decimal.Decimal // сколько теряем против Optimal: переплата при покупке, недополучено при продаже
+	CostDiffBps  float64         // то же относительно VWAP Optimal
+}
+
+const (
+	defaultSlippageBps = 10.0
+	// maxExactVenues — до стольких бирж перебираем все наборы, дальше — жадно
+	maxExactVenues = 10
+)
+
+func init() {
+	Register(Info{
+		ID:          "min_venues",
+		Title:       Text{RU: "Минимум бирж", EN: "Minimum venues"},
+		Description: Text{RU: "Наименьший набор бирж, у которого цена в пределах допуска от оптимальной", EN: "The fewest exchanges whose price stays within tolerance of the optimal one"},
+	}, MinVenues{})
+}
+
+func (MinVenues) Name() string {
+	return "Сценарий #5 (Минимум бирж в пределах допуска)"
+}
+
+func (s MinVenues) slippageBps(in Inputs) float64 {
+	switch {
+	case s.SlippageBps > 0:
+		return s.SlippageBps
+	case in.Params.SlippageBps > 0:
+		return in.Params.SlippageBps
+	}
+	return defaultSlippageBps
+}
+
+func (s MinVenues) Run(in Inputs) Result {
+	in.OrderBooks = in.FreshBooks() // устаревшие стаканы в расчёт не идут
+	full := Optimal{}.Run(in)
+	if len(full.Legs) == 0 || !full.AveragePrice.IsPositive() {
+		return full
+	}
+	tol := s.slippageBps(in)
+
+	// кандидаты — только биржи, которые Optimal вообще задействовал;
+	// порядок — по убыванию их доли, он же порядок жадного подбора
+	legs := append([]Leg(nil), full.Legs...)
+	sort.SliceStable(legs, func(i, j int) bool { return legs[i].AmountUSDT.GreaterThan(legs[j].AmountUSDT) })
+	names := make([]string, len(legs))
+	for i, l := range legs {
+		names[i] = l.Exchange
+	}
+
+	try := func(mask uint) (Result, bool) {
+		sub := in
+		sub.OrderBooks = make(map[string]*domain.OrderBook, bits.OnesCount(mask))
+		for i, ex := range names {
+			if mask&(1<<i) != 0 {
+				sub.OrderBooks[ex] = in.OrderBooks[ex]
+			}
+		}
+		r := Optimal{}.Run(sub)
+		return r, withinTolerance(in.Direction, full, r, tol)
+	}
+
+	best, bestMask := full, uint(1)<<len(names)-1
+	if n := len(names); n <= maxExactVenues {
+		// наборы по возрастанию размера; среди наборов одного размера — лучший VWAP
+		for k := 1; k < n; k++ {
+			found := false
+			for mask := uint(1); mask < 1<<n; mask++ {
+				if bits.OnesCount(mask) != k {
+					continue
+				}
+				r, ok := try(mask)
+				if ok && (!found || better(in.Direction, r, best)) {
+					best, bestMask, found = r, mask, true
+				}
+			}
+			if found {
+				break
+			}
+		}
+	} else {
+		for k := 1; k < n; k++ {
+			mask := uint(1)<<k - 1
+			if r, ok := try(mask); ok {
+				best, bestMask = r, mask
+				break
+			}
+		}
+	}
+
+	sel := &VenueSelection{ToleranceBps: tol, OptimalVWAP: full.AveragePrice}
+	for i, ex := range names {
+		if bestMask&(1<<i) != 0 {
+			sel.Exchanges = append(sel.Exchanges, ex)
+		}
+	}
+	sort.Strings(sel.Exchanges)
+	switch in.Direction {
+	case Buy:
+		sel.CostDiffUSDT = best.AveragePrice.Sub(full.AveragePrice).Mul(best.TotalQty)
+	case Sell:
+		sel.CostDiffUSDT = full.TotalUSDT.Sub(best.TotalUSDT)
+	}
+	sel.CostDiffBps = slippage(in.Direction, full.AveragePrice, best.AveragePrice)
+	best.Venues = sel
+	return best
+}
+
+// withinTolerance — набор исполняет не меньше Optimal и VWAP хуже не больше чем на tol б.п.
+func withinTolerance(dir Direction, full, r Result, tol float64) bool {
+	const eps = 1e-9
+	if !r.TotalQty.IsPositive() || !r.AveragePrice.IsPositive() {
+		return false
+	}
+	// «не меньше» — с точностью до пылинок от округления частичного уровня
+	need := decimal.NewFromFloat(1 - eps)
+	filled := r.TotalUSDT.GreaterThanOrEqual(full.TotalUSDT.Mul(need)) // при покупке — потратили бюджет
+	if dir == Sell {
+		filled = r.TotalQty.GreaterThanOrEqual(full.TotalQty.Mul(need)) // при продаже — продали объём
+	}
+	return filled && slippage(dir, full.AveragePrice, r.AveragePrice) <= tol+eps
+}
+
+// slippage — насколько vwap хуже опорной цены, б.п. (при покупке хуже — дороже).
+func slippage(dir Direction, ref, vwap decimal.Decimal) float64 {
+	diff := vwap.Sub(ref)
+	if dir == Sell {
+		diff = ref.Sub(vwap)
+	}
+	return diff.Div(ref).InexactFloat64() * 10000
+}
+
+func better(dir Direction, a, b Result) bool {
+	if dir == Sell {
+		return a.AveragePrice.GreaterThan(b.AveragePrice)
+	}
+	return a.AveragePrice.LessThan(b.AveragePrice)
+}
@@ -3,31 +3,40 @@ package scenario
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
+
 	"cryptobot/internal/domain"
 )
 
+// Суммы и количества — десятичные (см. пакет money): итоги сходятся с ножками
+// точно. Цены уровней стакана для сравнения и ранжирования остаются float64;
+// Optimal и по уровням идёт во float64 и переводит в decimal только итоги ножек.
+
 type ExchangeEval struct {
 	Exchange   string
-	AvgPrice   float64
-	Qty        float64
-	AmountUSDT float64
+	AvgPrice   decimal.Decimal
+	Qty        decimal.Decimal
+	AmountUSDT decimal.Decimal
 	Coverage   float64
 }
 
 type Leg struct {
 	Exchange   string
-	Price      float64
-	Qty        float64
-	AmountUSDT float64
+	Price      decimal.Decimal
+	Qty        decimal.Decimal
+	AmountUSDT decimal.Decimal
 }
 
 type Result struct {
 	Legs         []Leg
-	TotalQty     float64
-	TotalUSDT    float64
-	AveragePrice float64
-	Leftover     float64
+	TotalQty     decimal.Decimal
+	TotalUSDT    decimal.Decimal
+	AveragePrice decimal.Decimal
+	Leftover     decimal.Decimal
 	Asset        string
+	Venues       *VenueSelection // только MinVenues: выбранный набор бирж
+
+	RiskAdjustedUSDT decimal.Decimal // TotalUSDT по ценам с поправкой на риск бирж; 0 — штрафы не заданы
 }
 
 type Direction int
@@ -41,8 +50,41 @@ type Inputs struct {
 	Direction  Direction
 	Symbol     string
 	Right      string
-	Amount     float64
+	Amount     decimal.Decimal
 	OrderBooks map[string]*domain.OrderBook
 	Now        time.Time
-	MaxStale   time.Duration
+	MaxStale   time.Duration // стаканы старше (по времени биржи) в расчёт не идут; 0 — без проверки
+	Params     Params
+}
+
+// Params — настройки сценариев из конфига; нулевые значения — умолчания сценария.
+type Params struct {
+	LiquidityBandPct float64 // LiquiditySplit: полоса от лучшей цены, %
+	SlippageBps      float64 // MinVenues: допуск к VWAP Optimal, б.п.
+	// Optimal: штраф за риск биржи, б.п. (ключ — имя биржи в нижнем регистре).
+	// Рискованная биржа получает объём, только если дешевле на этот штраф.
+	RiskBps map[string]float64
+}
+
+// FreshBooks — стаканы, которые не старше MaxStale на момент Now. Стакан без
+// времени считается свежим: его возраст проверить нечем.
+func (in Inputs) FreshBooks() map[string]*domain.OrderBook {
+	if in.MaxStale <= 0 {
+		return in.OrderBooks
+	}
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	out := make(map[string]*domain.OrderBook, len(in.OrderBooks))
+	for ex, ob := range in.OrderBooks {
+		if ob == nil {
+			continue
+		}
+		if t := ob.Time(); !t.IsZero() && now.Sub(t) > in.MaxStale {
+			continue
+		}
+		out[ex] = ob
+	}
+	return out
 }
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/shared/format"
+	"cryptobot/internal/shared/money"
+	"cryptobot/internal/transport/cli"
+	"cryptobot/internal/usecase/scenario"
+)
+
+// WatchOptions — параметры режима наблюдения.
+type WatchOptions struct {
+	Params   cli.InputParams // пара и объём заявки, как в интерактивном режиме
+	Interval time.Duration
+	Route    RoutePlanner // обмен монета → монета считает planner.Service, как и веб
+}
+
+// Watch — полноэкранный dashboard: раз в Interval тянет стаканы со всех бирж
+// и пересчитывает план во всех сценариях, пока не отменён ctx.
+func Watch(ctx context.Context, cfg domain.Config, exchanges []domain.Exchange, opt WatchOptions) error {
+	if opt.Interval <= 0 {
+		return fmt.Errorf("watch interval must be > 0")
+	}
+	if opt.Params.Action == "swap" && opt.Route == nil {
+		return fmt.Errorf("watch: coin-to-coin swap needs a route planner")
+	}
+	strategies := registeredStrategies()
+
+	d := cli.NewDashboard(os.Stdout)
+	d.Start()
+	defer d.Stop()
+
+	t := time.NewTicker(opt.Interval)
+	defer t.Stop()
+	for {
+		d.Render(watchFrame(ctx, cfg, exchanges, strategies, opt))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+func watchFrame(ctx context.Context, cfg domain.Config, exchanges []domain.Exchange, strategies []strategy, opt WatchOptions) cli.DashboardFrame {
+	p := opt.Params
+	left := strings.ToUpper(p.LeftCoinName)
+	right := strings.ToUpper(p.RightCoinName)
+	dir, symbols := pairSymbols(p)
+
+	now := time.Now()
+	const maxStale = 10 * time.Second
+
+	decimals := 8
+	if left == "USDT" {
+		decimals = 2
+	}
+	f := cli.DashboardFrame{
+		Title:     fmt.Sprintf("%s → %s, %s %s", left, right, format.FloatRU(p.LeftCoinVolume, decimals), left),
+		UpdatedAt: now,
+		Interval:  opt.Interval,
+		Direction: dir,
+	}
+
+	if p.Action == "swap" {
+		// стаканы и планы — из одного запроса planner.Service
+		return routeFrame(ctx, f, opt)
+	}
+
+	results := fetchAll(cfg, exchanges, symbols)
+	books := make(map[string]map[string]*domain.OrderBook, len(symbols))
+	for _, sym := range symbols {
+		books[sym] = map[string]*domain.OrderBook{}
+	}
+	for _, sym := range symbols {
+		for _, ex := range exchanges {
+			res := results[ex.Name()]
+			if ob := res.obs[sym]; res.err == nil && ob != nil {
+				books[sym][ex.Name()] = ob
+				f.Books = append(f.Books, ob)
+			}
+		}
+	}
+	for _, ex := range exchanges {
+		if res := results[ex.Name()]; res.err != nil {
+			f.Errors = append(f.Errors, fmt.Sprintf("%s: %v", ex.Name(), res.err))
+		}
+	}
+
+	in := scenario.Inputs{
+		Direction:  dir,
+		Symbol:     symbols[0],
+		Right:      right,
+		Amount:     money.FromFloat(p.LeftCoinVolume),
+		OrderBooks: books[symbols[0]],
+		Now:        now,
+		MaxStale:   maxStale,
+	}
+	f.Results = make(map[string]scenario.Result, len(strategies))
+	for _, st := range strategies {
+		f.Results[st.Name()] = st.Run(in)
+	}
+	return f
+}
+
+// routeFrame — кадр обмена монета → монета: панель стаканов показывает те же
+// стаканы, по которым planner.Service посчитал маршруты под ней.
+func routeFrame(ctx context.Context, f cli.DashboardFrame, opt WatchOptions) cli.DashboardFrame {
+	p := opt.Params
+	f.From, f.To = strings.ToUpper(p.LeftCoinName), strings.ToUpper(p.RightCoinName)
+	ctx, cancel := context.WithTimeout(ctx, cli.DefaultTimeout)
+	defer cancel()
+	res, err := opt.Route.Compare(ctx, p.PlanRequest())
+	if err != nil {
+		f.Errors = append(f.Errors, "planner: "+err.Error())
+		return f
+	}
+	f.Books = routeBooks(res)
+	for _, d := range res.Diagnostics {
+		if strings.Contains(d, ":err") {
+			f.Errors = append(f.Errors, d)
+		}
+	}
+	f.Route = cli.RouteSummaries(res)
+	return f
+}
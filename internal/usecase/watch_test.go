@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cryptobot/internal/domain"
+	"cryptobot/internal/transport/cli"
+	"cryptobot/internal/usecase/planner"
+)
+
+// countingExchange считает запросы стаканов к бирже.
+type countingExchange struct{ calls int }
+
+func (e *countingExchange) Name() string                  { return "okx" }
+func (e *countingExchange) GetSymbols() ([]string, error) { return nil, nil }
+func (e *countingExchange) GetOrderBook(string, int) (*domain.OrderBook, error) {
+	e.calls++
+	return nil, nil
+}
+func (e *countingExchange) GetMultipleOrderBooks([]string, int, time.Duration) (map[string]*domain.OrderBook, error) {
+	e.calls++
+	return nil, nil
+}
+
+func TestWatchSwapFrameFetchesOnce(t *testing.T) {
+	ex := &countingExchange{}
+	route := &fakeRoute{res: planner.CompareResult{
+		Base: "BTC", Quote: "ETH",
+		Diagnostics: []string{"bybit:err:timeout", "gate:stale:excluded:ETH age=12s > 10s"},
+		Books: map[string][]planner.Book{
+			"ETH": {{Exchange: "okx"}},
+			"BTC": {{Exchange: "okx"}},
+		},
+	}}
+	opt := WatchOptions{
+		Params:   cli.InputParams{Action: "swap", LeftCoinName: "eth", LeftCoinVolume: 1, RightCoinName: "btc"},
+		Interval: time.Second,
+		Route:    route,
+	}
+	f := watchFrame(context.Background(), domain.Config{}, []domain.Exchange{ex}, nil, opt)
+	if ex.calls != 0 || route.calls != 1 {
+		t.Errorf("exchange calls = %d, planner calls = %d; want 0 and 1", ex.calls, route.calls)
+	}
+	if len(f.Books) != 2 || f.Books[0].Symbol != "ETHUSDT" || f.Books[1].Symbol != "BTCUSDT" {
+		t.Errorf("books = %+v", f.Books)
+	}
+	if len(f.Errors) != 1 || f.Errors[0] != "bybit:err:timeout" {
+		t.Errorf("errors = %q", f.Errors)
+	}
+	if f.From != "ETH" || f.To != "BTC" {
+		t.Errorf("route = %s → %s", f.From, f.To)
+	}
+}